@@ -0,0 +1,49 @@
+package a800mon
+
+import (
+	"context"
+	"fmt"
+
+	"go800mon/internal/memory"
+)
+
+// rpcAddrSource reads registers and vectors for address expressions,
+// fetching the CPU state at most once per expression.
+type rpcAddrSource struct {
+	ctx context.Context
+	rpc *RpcClient
+	cpu *CPUState
+}
+
+func (s *rpcAddrSource) Register(name string) (uint16, error) {
+	if s.cpu == nil {
+		cpu, err := s.rpc.CPUState(s.ctx)
+		if err != nil {
+			return 0, err
+		}
+		s.cpu = &cpu
+	}
+	switch name {
+	case "PC":
+		return s.cpu.PC, nil
+	case "S":
+		return uint16(s.cpu.S), nil
+	case "X":
+		return uint16(s.cpu.X), nil
+	case "Y":
+		return uint16(s.cpu.Y), nil
+	case "P":
+		return uint16(s.cpu.P), nil
+	}
+	return 0, fmt.Errorf("Unknown register: %s", name)
+}
+
+func (s *rpcAddrSource) ReadWord(addr uint16) (uint16, error) {
+	return s.rpc.ReadVector(s.ctx, addr)
+}
+
+// ParseAddress evaluates an address expression (see memory.ParseAddr),
+// reading registers and [vector] words from the emulator.
+func (r *RpcClient) ParseAddress(ctx context.Context, text string) (uint16, error) {
+	return memory.ParseAddr(text, &rpcAddrSource{ctx: ctx, rpc: r})
+}
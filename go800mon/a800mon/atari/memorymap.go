@@ -1,15 +1,12 @@
 package atari
 
 import (
-	"regexp"
 	"strings"
 
 	"go800mon/internal/memory"
 	imap "go800mon/internal/memorymap"
 )
 
-var plainHexAddrRe = regexp.MustCompile(`^[0-9A-Fa-f]{1,4}$`)
-
 func LookupSymbol(addr uint16) string {
 	return imap.Lookup(addr)
 }
@@ -28,10 +25,7 @@ func FindSymbolOrAddress(query string) (uint16, bool) {
 	if q == "" {
 		return 0, false
 	}
-	if !plainHexAddrRe.MatchString(q) {
-		return 0, false
-	}
-	addr, err := memory.ParseHex(q)
+	addr, err := memory.ParseAddr(q, nil)
 	if err != nil {
 		return 0, false
 	}
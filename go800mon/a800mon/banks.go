@@ -0,0 +1,143 @@
+package a800mon
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// PORTB is the PIA port that selects the extended RAM bank seen at
+// $4000-$7FFF on XE machines.
+const PORTB uint16 = 0xD301
+
+const (
+	portbCPUBank   byte = 0x10
+	portbANTICBank byte = 0x20
+)
+
+// BankModel decodes the extended RAM bits of PORTB for one machine type.
+// Bit 4 low gives the CPU the selected bank; on machines with
+// SeparateANTIC, bit 5 low does the same for ANTIC, otherwise ANTIC
+// follows the CPU.
+type BankModel struct {
+	Banks         int
+	SeparateANTIC bool
+	// bits lists the PORTB bits of the bank number, lowest first.
+	bits []uint
+}
+
+// MainBank is the bank number of the base 64K.
+const MainBank = -1
+
+func BankModelFor(machineType byte) BankModel {
+	switch machineType {
+	case StatusMachineAtari130XE:
+		return BankModel{Banks: 4, SeparateANTIC: true, bits: []uint{2, 3}}
+	case StatusMachineAtari320XECompyShop:
+		return BankModel{Banks: 16, SeparateANTIC: true, bits: []uint{2, 3, 6, 7}}
+	case StatusMachineAtari320XERambo:
+		return BankModel{Banks: 16, bits: []uint{2, 3, 5, 6}}
+	case StatusMachineAtari576XE:
+		return BankModel{Banks: 32, bits: []uint{1, 2, 3, 5, 6}}
+	case StatusMachineAtari1088XE:
+		return BankModel{Banks: 64, bits: []uint{1, 2, 3, 5, 6, 7}}
+	}
+	return BankModel{}
+}
+
+// Decode returns the banks the CPU and ANTIC see at $4000-$7FFF, MainBank
+// when a bank is not enabled.
+func (m BankModel) Decode(portb byte) (cpu, antic int) {
+	if m.Banks == 0 {
+		return MainBank, MainBank
+	}
+	bank := 0
+	for i, bit := range m.bits {
+		if portb&(1<<bit) != 0 {
+			bank |= 1 << i
+		}
+	}
+	cpu, antic = MainBank, MainBank
+	if portb&portbCPUBank == 0 {
+		cpu = bank
+	}
+	switch {
+	case m.SeparateANTIC && portb&portbANTICBank == 0:
+		antic = bank
+	case !m.SeparateANTIC:
+		antic = cpu
+	}
+	return cpu, antic
+}
+
+// Select returns portb changed to give the CPU bank, or main RAM for
+// MainBank. Other bits are kept.
+func (m BankModel) Select(portb byte, bank int) (byte, error) {
+	if bank == MainBank {
+		return portb | portbCPUBank, nil
+	}
+	if bank < 0 || bank >= m.Banks {
+		if m.Banks == 0 {
+			return 0, errors.New("This machine has no extended RAM banks.")
+		}
+		return 0, fmt.Errorf("Bank %d is out of range (0-%d).", bank, m.Banks-1)
+	}
+	portb &^= portbCPUBank
+	for i, bit := range m.bits {
+		if bank&(1<<i) != 0 {
+			portb |= 1 << bit
+		} else {
+			portb &^= 1 << bit
+		}
+	}
+	return portb, nil
+}
+
+// Label describes the banks PORTB selects, or "" for machines without
+// extended RAM.
+func (m BankModel) Label(portb byte) string {
+	if m.Banks == 0 {
+		return ""
+	}
+	cpu, antic := m.Decode(portb)
+	label := "BANK " + bankName(cpu)
+	if antic != cpu {
+		label += " A:" + bankName(antic)
+	}
+	return label
+}
+
+func bankName(bank int) string {
+	if bank == MainBank {
+		return "main"
+	}
+	return fmt.Sprint(bank)
+}
+
+// WithBank runs fn with bank switched in for the CPU and puts PORTB back
+// afterwards. The emulator must be paused so nothing else sees the switch.
+func (r *RpcClient) WithBank(ctx context.Context, bank int, fn func() error) error {
+	st, err := r.Status(ctx)
+	if err != nil {
+		return err
+	}
+	if !st.Paused {
+		return errors.New("Pause the emulator to access a RAM bank.")
+	}
+	pia, err := r.PIAState(ctx)
+	if err != nil {
+		return err
+	}
+	value, err := BankModelFor(st.MachineType).Select(pia.PORTB, bank)
+	if err != nil {
+		return err
+	}
+	if err := r.WriteMemory(ctx, PORTB, []byte{value}); err != nil {
+		return err
+	}
+	fnErr := fn()
+	if err := r.WriteMemory(ctx, PORTB, []byte{pia.PORTB}); err != nil && fnErr == nil {
+		return err
+	}
+	return fnErr
+}
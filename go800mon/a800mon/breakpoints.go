@@ -101,14 +101,14 @@ func ParseBPCondition(expr string) (BreakpointCondition, error) {
 	if t, ok := bpConditionTypes[leftKey]; ok {
 		cond.Type = t
 	} else if strings.HasPrefix(leftKey, "mem[") && strings.HasSuffix(leftKey, "]") {
-		addr, err := memory.ParseHex(leftKey[4 : len(leftKey)-1])
+		addr, err := memory.ParseAddr(leftKey[4:len(leftKey)-1], nil)
 		if err != nil {
 			return BreakpointCondition{}, fmt.Errorf("invalid memory address in condition: %s", expr)
 		}
 		cond.Type = 9
 		cond.Addr = addr
 	} else if strings.HasPrefix(leftKey, "mem:") {
-		addr, err := memory.ParseHex(leftKey[4:])
+		addr, err := memory.ParseAddr(leftKey[4:], nil)
 		if err != nil {
 			return BreakpointCondition{}, fmt.Errorf("invalid memory address in condition: %s", expr)
 		}
@@ -117,7 +117,7 @@ func ParseBPCondition(expr string) (BreakpointCondition, error) {
 	} else {
 		return BreakpointCondition{}, fmt.Errorf("invalid breakpoint source in condition: %s", expr)
 	}
-	value, err := memory.ParseHex(valueText)
+	value, err := memory.ParseAddr(valueText, nil)
 	if err != nil {
 		return BreakpointCondition{}, fmt.Errorf("invalid breakpoint value in condition: %s", expr)
 	}
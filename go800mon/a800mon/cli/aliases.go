@@ -8,6 +8,10 @@ type Command = mon.Command
 type CommandError = mon.CommandError
 type StackState = mon.StackState
 type Trainer = mon.Trainer
+type PointerScanner = mon.PointerScanner
+type MemoryRange = mon.MemoryRange
+type MemorySnapshot = mon.MemorySnapshot
+type CartSlotState = mon.CartSlotState
 
 const (
 	CmdPing            = mon.CmdPing
@@ -32,23 +36,27 @@ const (
 	DMACTLAddr   = atari.DMACTLAddr
 	DMACTLHWAddr = atari.DMACTLHWAddr
 	DLPTRSAddr   = atari.DLPTRSAddr
+
+	PointerChainLimit = mon.PointerChainLimit
 )
 
 var (
-	RunMonitor                  = mon.RunMonitor
-	NewRpcClient                = mon.NewRpcClient
-	NewSocketTransport          = mon.NewSocketTransport
-	NewTrainer                  = mon.NewTrainer
-	ParseBPClauses              = mon.ParseBPClauses
-	FormatBPCondition           = mon.FormatBPCondition
-	StatusMachineName           = mon.StatusMachineName
-	StatusMachineFamilyName     = mon.StatusMachineFamilyName
-	StatusOSRevisionName        = mon.StatusOSRevisionName
-	StatusBasicRevisionName     = mon.StatusBasicRevisionName
+	RunMonitor                    = mon.RunMonitor
+	NewRpcClient                  = mon.NewRpcClient
+	NewSocketTransport            = mon.NewSocketTransport
+	NewTrainer                    = mon.NewTrainer
+	NewPointerScanner             = mon.NewPointerScanner
+	LoadSnapshot                  = mon.LoadSnapshot
+	ParseBPClauses                = mon.ParseBPClauses
+	FormatBPCondition             = mon.FormatBPCondition
+	StatusMachineName             = mon.StatusMachineName
+	StatusMachineFamilyName       = mon.StatusMachineFamilyName
+	StatusOSRevisionName          = mon.StatusOSRevisionName
+	StatusBasicRevisionName       = mon.StatusBasicRevisionName
 	StatusBuiltinGameRevisionName = mon.StatusBuiltinGameRevisionName
-	EncodeATASCIIText           = atari.EncodeATASCIIText
-	ATASCIIToScreen             = atari.ATASCIIToScreen
-	DecodeDisplayList           = atari.DecodeDisplayList
+	EncodeATASCIIText             = atari.EncodeATASCIIText
+	ATASCIIToScreen               = atari.ATASCIIToScreen
+	DecodeDisplayList             = atari.DecodeDisplayList
 )
 
 func formatCPU(cpu mon.CPUState) string {
@@ -0,0 +1,161 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+
+	"go800mon/internal/atr"
+	"go800mon/internal/disasm"
+)
+
+// bootCodeOffset is where execution starts in the loaded boot sectors.
+const bootCodeOffset = 6
+
+func loadAtr(path string) (*atr.Image, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return atr.Parse(data)
+}
+
+func cmdAtrInfo(args cliAtrInfoCmd) int {
+	im, err := loadAtr(args.File)
+	if err != nil {
+		return fail(err)
+	}
+	fmt.Printf("Sectors: %d x %d bytes (%s)\n", im.Sectors, im.SectorSize, atrDensity(im))
+	rec, code, err := im.Boot()
+	if err != nil {
+		return fail(err)
+	}
+	fmt.Printf("Boot: %d sectors at %04X, init %04X, flags %02X\n", rec.Sectors, rec.Load, rec.Init, rec.Flags)
+	if vtoc, err := im.VTOC(); err != nil {
+		fmt.Println("VTOC: none")
+	} else {
+		fmt.Printf("VTOC: code %02X, %d sectors, %d free\n", vtoc.Code, vtoc.Total, vtoc.Free)
+	}
+	if files, err := im.Files(); err == nil {
+		fmt.Printf("Files: %d\n", len(files))
+	}
+	if !args.Disasm {
+		return 0
+	}
+	if len(code) <= bootCodeOffset {
+		return fail(fmt.Errorf("Boot record loads no code."))
+	}
+	fmt.Println()
+	start := rec.Load + bootCodeOffset
+	for _, line := range disasm.Disasm(start, code[bootCodeOffset:], false) {
+		fmt.Println(line)
+	}
+	return 0
+}
+
+func atrDensity(im *atr.Image) string {
+	switch {
+	case im.SectorSize == 128 && im.Sectors == 1040:
+		return "enhanced density"
+	case im.SectorSize == 128:
+		return "single density"
+	case im.SectorSize == 256:
+		return "double density"
+	}
+	return "large sectors"
+}
+
+func cmdAtrList(args cliAtrFileCmd) int {
+	im, err := loadAtr(args.File)
+	if err != nil {
+		return fail(err)
+	}
+	files, err := im.Files()
+	if err != nil {
+		return fail(err)
+	}
+	for _, f := range files {
+		fmt.Printf("%s %4d %4d %s\n", atrFlags(f), f.Sectors, f.Start, f.Path)
+	}
+	return 0
+}
+
+// atrFlags renders the entry flags the way DOS menus do: '*' for locked
+// files, ':' for subdirectories.
+func atrFlags(f atr.File) string {
+	lock, dir := " ", " "
+	if f.Flags&atr.FlagLocked != 0 {
+		lock = "*"
+	}
+	if f.IsDir() {
+		dir = ":"
+	}
+	return lock + dir
+}
+
+func cmdAtrExtract(args cliAtrExtractCmd) int {
+	im, err := loadAtr(args.File)
+	if err != nil {
+		return fail(err)
+	}
+	files, err := im.Files()
+	if err != nil {
+		return fail(err)
+	}
+	byPath := map[string]atr.File{}
+	for _, f := range files {
+		byPath[strings.ToUpper(f.Path)] = f
+	}
+	var selected []atr.File
+	if len(args.Names) == 0 {
+		for _, f := range files {
+			if !f.IsDir() {
+				selected = append(selected, f)
+			}
+		}
+	}
+	for _, name := range args.Names {
+		f, ok := byPath[strings.ToUpper(name)]
+		if !ok {
+			return fail(fmt.Errorf("File not found: %s.", name))
+		}
+		selected = append(selected, f)
+	}
+	for _, f := range selected {
+		data, err := im.ReadFile(f)
+		if err != nil {
+			return fail(err)
+		}
+		out, err := extractPath(args.Output, f.Path)
+		if err != nil {
+			return fail(err)
+		}
+		if err := os.MkdirAll(filepath.Dir(out), 0o755); err != nil {
+			return fail(err)
+		}
+		if err := os.WriteFile(out, data, 0o644); err != nil {
+			return fail(err)
+		}
+		fmt.Printf("%s -> %s (%d bytes)\n", f.Path, out, len(data))
+	}
+	return 0
+}
+
+// extractPath maps a disk path to a file under output. Each '>' separated
+// part must be a plain file name and the result must stay inside output.
+func extractPath(output, path string) (string, error) {
+	parts := strings.Split(path, ">")
+	for _, part := range parts {
+		if part == "" || part == "." || part == ".." || strings.ContainsAny(part, `/\`) || strings.IndexFunc(part, unicode.IsControl) >= 0 {
+			return "", fmt.Errorf("Unsafe file name on disk: %q.", path)
+		}
+	}
+	out := filepath.Join(output, filepath.Join(parts...))
+	rel, err := filepath.Rel(output, out)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("Unsafe file name on disk: %q.", path)
+	}
+	return out, nil
+}
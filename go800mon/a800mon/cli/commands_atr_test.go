@@ -0,0 +1,18 @@
+package cli
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestExtractPath(t *testing.T) {
+	out := t.TempDir()
+	if got, err := extractPath(out, "GAMES>PACMAN.XEX"); err != nil || got != filepath.Join(out, "GAMES", "PACMAN.XEX") {
+		t.Fatalf("extractPath = %q, %v", got, err)
+	}
+	for _, path := range []string{"..", "GAMES>..>..>X", "../X", `..\X`, "A>>B", "BAD\x1b"} {
+		if got, err := extractPath(out, path); err == nil {
+			t.Errorf("extractPath(%q) = %q, want error", path, got)
+		}
+	}
+}
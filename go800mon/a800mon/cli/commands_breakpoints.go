@@ -2,7 +2,6 @@ package cli
 
 import (
 	"context"
-	"encoding/binary"
 	"errors"
 	"fmt"
 	"strings"
@@ -99,24 +98,18 @@ func blineModeName(mode byte) string {
 }
 
 func cmdBLine(socket string, args cliBLineCmd) int {
-	var payload []byte
+	var line *uint16
 	if args.Scanline != nil {
 		scanline, err := memory.ParseHex(*args.Scanline)
 		if err != nil {
 			return fail(err)
 		}
-		payload = make([]byte, 2)
-		binary.LittleEndian.PutUint16(payload, scanline)
+		line = &scanline
 	}
-	data, err := rpcClient(socket).Call(context.Background(), CmdBLine, payload)
+	scanline, mode, err := rpcClient(socket).BLine(context.Background(), line)
 	if err != nil {
 		return fail(err)
 	}
-	if len(data) < 3 {
-		return fail(errors.New("BLINE payload too short"))
-	}
-	scanline := binary.LittleEndian.Uint16(data[0:2])
-	mode := blineModeName(data[2])
-	fmt.Printf("scanline=%d mode=%s\n", scanline, mode)
+	fmt.Printf("scanline=%d mode=%s\n", scanline, blineModeName(mode))
 	return 0
 }
@@ -3,6 +3,9 @@ package cli
 import (
 	"context"
 	"fmt"
+	"os"
+
+	"go800mon/internal/cartridge"
 )
 
 func cmdCartState(socket string) int {
@@ -12,18 +15,60 @@ func cmdCartState(socket string) int {
 	}
 	fmt.Printf("autoreboot:    %d\n", state.Autoreboot)
 	fmt.Printf("main_present:  %d\n", state.Main.Present)
-	fmt.Printf("main_type:     %d\n", state.Main.Type)
+	fmt.Printf("main_type:     %d (%s)\n", state.Main.Type, cartridge.Name(int(state.Main.Type)))
 	fmt.Printf("main_state:    %08X\n", state.Main.State)
+	fmt.Printf("main_bank:     %s\n", cartBank(state.Main))
 	fmt.Printf("main_size_kb:  %d\n", state.Main.SizeKB)
 	fmt.Printf("main_raw:      %d\n", state.Main.Raw)
 	fmt.Printf("piggy_present: %d\n", state.Piggy.Present)
-	fmt.Printf("piggy_type:    %d\n", state.Piggy.Type)
+	fmt.Printf("piggy_type:    %d (%s)\n", state.Piggy.Type, cartridge.Name(int(state.Piggy.Type)))
 	fmt.Printf("piggy_state:   %08X\n", state.Piggy.State)
+	fmt.Printf("piggy_bank:    %s\n", cartBank(state.Piggy))
 	fmt.Printf("piggy_size_kb: %d\n", state.Piggy.SizeKB)
 	fmt.Printf("piggy_raw:     %d\n", state.Piggy.Raw)
 	return 0
 }
 
+func cartBank(slot CartSlotState) string {
+	t, ok := cartridge.Lookup(int(slot.Type))
+	if slot.Present == 0 || !ok {
+		return "-"
+	}
+	if bank := t.DescribeBank(slot.State); bank != "" {
+		return bank
+	}
+	return "fixed"
+}
+
+func cmdCartInfo(args cliCartInfoCmd) int {
+	data, err := os.ReadFile(args.File)
+	if err != nil {
+		return fail(err)
+	}
+	car, err := cartridge.ParseCAR(data)
+	if err != nil {
+		return fail(err)
+	}
+	fmt.Printf("Type:     %d (%s)\n", car.Type, cartridge.Name(car.Type))
+	if t, ok := cartridge.Lookup(car.Type); ok {
+		fmt.Printf("Size:     %d KB\n", t.SizeKB)
+		if t.Banks() > 1 {
+			fmt.Printf("Banks:    %d x %d KB\n", t.Banks(), t.BankKB)
+		}
+		fmt.Printf("Switch:   %s\n", t.Scheme)
+	}
+	fmt.Printf("Checksum: %08X\n", car.Checksum)
+	problems := car.Problems()
+	if len(problems) == 0 {
+		fmt.Println("Checksum and size match the data.")
+		return 0
+	}
+	for _, p := range problems {
+		fmt.Println(p)
+	}
+	return 1
+}
+
 func cmdCartRemove(socket string) int {
 	return cmdSimple(socket, CmdRemoveCartrige)
 }
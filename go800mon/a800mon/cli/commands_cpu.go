@@ -6,8 +6,6 @@ import (
 	"errors"
 	"fmt"
 	"strings"
-
-	"go800mon/internal/memory"
 )
 
 func cmdCPUState(socket string) int {
@@ -16,14 +14,15 @@ func cmdCPUState(socket string) int {
 
 func cmdSetReg(socket string, args cliSetRegCmd) int {
 	target := setRegTargets[strings.ToLower(args.Target)]
-	value, err := memory.ParseHex(args.Value)
+	cl := rpcClient(socket)
+	value, err := cl.ParseAddress(context.Background(), args.Value)
 	if err != nil {
 		return fail(err)
 	}
 	payload := make([]byte, 3)
 	payload[0] = target
 	binary.LittleEndian.PutUint16(payload[1:3], value)
-	if _, err := rpcClient(socket).Call(context.Background(), CmdSetReg, payload); err != nil {
+	if _, err := cl.Call(context.Background(), CmdSetReg, payload); err != nil {
 		return fail(err)
 	}
 	return 0
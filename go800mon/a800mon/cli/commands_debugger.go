@@ -13,7 +13,6 @@ import (
 	"time"
 
 	"go800mon/internal/disasm"
-	"go800mon/internal/memory"
 )
 
 const debugShellHelpText = "commands: pause(p), step(s), stepvbl(v), untilret(r [pc]), continue(c), stack(t), q"
@@ -81,7 +80,7 @@ func cmdDebugShell(socket string) int {
 			}
 			var payload []byte
 			if len(parts) == 2 {
-				pc, parseErr := memory.ParseHex(parts[1])
+				pc, parseErr := cl.ParseAddress(context.Background(), parts[1])
 				if parseErr != nil {
 					fmt.Println(parseErr)
 					continue
@@ -202,7 +201,7 @@ func cmdHistory(socket string, count int) int {
 	}
 	for i := len(entries) - 1; i >= 0; i-- {
 		e := entries[i]
-		ins := disasm.DisasmOne(e.PC, e.OpBytes())
+		ins := disasm.DisasmOne(e.PC, e.OpBytes(), false)
 		fmt.Printf("%03d Y=%02X X=%02X PC=%04X  %s\n", (len(entries) - i), e.Y, e.X, e.PC, ins)
 	}
 	return 0
@@ -0,0 +1,137 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+
+	"go800mon/internal/memory"
+	"go800mon/internal/memorymap"
+)
+
+func cmdMemPointers(socket string, args cliMemPointersCmd) int {
+	ctx := context.Background()
+	cl := rpcClient(socket)
+	defer cl.Close()
+	target, err := cl.ParseAddress(ctx, args.Target)
+	if err != nil {
+		return fail(err)
+	}
+	maxOffset, err := cl.ParseAddress(ctx, args.MaxOffset)
+	if err != nil {
+		return fail(err)
+	}
+	scanner, err := NewPointerScanner(args.Depth, int(maxOffset))
+	if err != nil {
+		return fail(err)
+	}
+	scanner.BindReader(func(addr uint16, length int) ([]byte, error) {
+		return cl.ReadMemoryChunked(ctx, addr, length)
+	})
+	chains, err := scanner.Scan(target)
+	if err != nil {
+		return fail(err)
+	}
+	fmt.Printf(
+		"target=%04X depth=%d max_offset=$%X chains=%d\n",
+		target,
+		args.Depth,
+		maxOffset,
+		chains,
+	)
+	if scanner.Truncated() {
+		fmt.Printf("Stopped at %d chains; lower --max-offset or --depth.\n", PointerChainLimit)
+	}
+	if chains == 0 {
+		return 0
+	}
+	printPointerChains(scanner, 20)
+	fmt.Println("commands: n [target], p [limit], q")
+
+	reader := bufio.NewReader(os.Stdin)
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, os.Interrupt)
+	defer signal.Stop(sigCh)
+	for {
+		fmt.Print("pointers> ")
+		line, interrupted, err := readInteractiveLine(reader, sigCh)
+		if interrupted {
+			fmt.Println()
+			return 0
+		}
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				fmt.Println()
+				return 0
+			}
+			return fail(err)
+		}
+		parts := strings.Fields(line)
+		if len(parts) == 0 {
+			continue
+		}
+		switch strings.ToLower(parts[0]) {
+		case "q":
+			return 0
+		case "p":
+			if len(parts) > 2 {
+				fmt.Println("Usage: p [limit]")
+				continue
+			}
+			limit := 20
+			if len(parts) == 2 {
+				limit, err = memory.ParsePositiveInt(parts[1])
+				if err != nil {
+					fmt.Println(err)
+					continue
+				}
+			}
+			printPointerChains(scanner, limit)
+		case "n":
+			if len(parts) > 2 {
+				fmt.Println("Usage: n [target]")
+				continue
+			}
+			if len(parts) == 2 {
+				next, err := cl.ParseAddress(ctx, parts[1])
+				if err != nil {
+					fmt.Println(err)
+					continue
+				}
+				target = next
+			}
+			stable, err := scanner.Snapshot(target)
+			if err != nil {
+				return fail(err)
+			}
+			fmt.Printf("target=%04X snapshots=%d stable=%d\n", target, scanner.Snapshots(), stable)
+		default:
+			fmt.Println("Unknown command. Use: n [target], p [limit], q")
+		}
+	}
+}
+
+func printPointerChains(scanner *PointerScanner, limit int) {
+	total := scanner.ChainCount()
+	fmt.Printf("chains=%d stable=%d\n", total, scanner.StableCount())
+	if total == 0 {
+		return
+	}
+	rows := scanner.Chains(limit)
+	fmt.Println("idx  hits  chain")
+	for i, chain := range rows {
+		line := fmt.Sprintf("%03d  %d/%d  %s", i+1, chain.Hits, scanner.Snapshots(), chain.Expr())
+		if name := memorymap.Lookup(chain.Base); name != "" {
+			line += "  " + name
+		}
+		fmt.Println(line)
+	}
+	if len(rows) < total {
+		fmt.Printf("... %d more\n", total-len(rows))
+	}
+}
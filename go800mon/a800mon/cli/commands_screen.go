@@ -3,11 +3,14 @@ package cli
 import (
 	"context"
 	"fmt"
+	"image"
+	"image/png"
 	"os"
 	"strings"
 
 	dl "go800mon/internal/displaylist"
 	"go800mon/internal/memory"
+	"go800mon/internal/video"
 )
 
 func cmdDumpDList(socket string, args cliDListCmd) int {
@@ -25,7 +28,7 @@ func cmdDumpDList(socket string, args cliDListCmd) int {
 		}
 		dump, err = cl.ReadDisplayList(ctx)
 	} else {
-		start, err = memory.ParseHex(*args.Address)
+		start, err = cl.ParseAddress(ctx, *args.Address)
 		if err != nil {
 			return fail(err)
 		}
@@ -62,6 +65,38 @@ func cmdDumpDList(socket string, args cliDListCmd) int {
 			fmt.Printf("#%d %04X-%04X len=%04X antic=%d\n", i+1, seg.Start, last, length, seg.Mode)
 		}
 	}
+	if !args.Lint && !args.Scanlines {
+		return 0
+	}
+	antic, err := cl.ANTICState(ctx)
+	if err != nil {
+		return fail(err)
+	}
+	if args.Scanlines {
+		fmt.Println()
+		fmt.Println("Line VC Entry Row DMA DLI")
+		for _, sl := range dlist.Scanlines(dmactl, antic.VSCROL) {
+			dli := ""
+			if sl.DLI {
+				dli = "DLI"
+			}
+			fmt.Printf("%4d %02X %04X  %3d %3d %-3s %s\n", sl.Line, sl.Line/2, sl.Entry.Addr, sl.Row, sl.DMA, dli, sl.Entry.Description())
+		}
+	}
+	if args.Lint {
+		info, err := cl.Sysinfo(ctx)
+		if err != nil {
+			return fail(err)
+		}
+		fmt.Println()
+		issues := dlist.Lint(dmactl, antic.NMIEN, info.TVPAL)
+		if len(issues) == 0 {
+			fmt.Println("No issues.")
+		}
+		for _, issue := range issues {
+			fmt.Printf("%04X: %s\n", issue.Addr, issue.Message)
+		}
+	}
 	return 0
 }
 
@@ -149,6 +184,9 @@ func fmtBytes(values []byte) string {
 }
 
 func cmdScreen(socket string, args cliScreenCmd) int {
+	if args.PNG != "" {
+		return cmdScreenPNG(socket, args.PNG)
+	}
 	if args.List && args.Segment != nil {
 		fmt.Fprintln(os.Stderr, "--list cannot be used with a segment number")
 		return 1
@@ -308,6 +346,100 @@ func cmdScreen(socket string, args cliScreenCmd) int {
 	)
 }
 
+func cmdScreenPNG(socket string, path string) int {
+	img, err := rpcClient(socket).RenderScreen(context.Background())
+	if err != nil {
+		return fail(err)
+	}
+	if err := writePNG(path, img); err != nil {
+		return fail(err)
+	}
+	return 0
+}
+
+func cmdDumpCharset(socket string, args cliCharsetCmd) int {
+	cl := rpcClient(socket)
+	ctx := context.Background()
+	regs, palette, err := cl.VideoRegisters(ctx)
+	if err != nil {
+		return fail(err)
+	}
+	addr := uint16(regs.CHBASE&0xFC) << 8
+	if args.Address != nil {
+		if addr, err = cl.ParseAddress(ctx, *args.Address); err != nil {
+			return fail(err)
+		}
+	}
+	font, err := cl.ReadMemoryChunked(ctx, addr, video.CharsetSize)
+	if err != nil {
+		return fail(err)
+	}
+	if args.FNT != "" {
+		if err := os.WriteFile(args.FNT, font, 0o644); err != nil {
+			return fail(err)
+		}
+	}
+	if args.PNG != "" {
+		if err := writePNG(args.PNG, video.CharsetImage(font, args.Multicolor, regs, palette)); err != nil {
+			return fail(err)
+		}
+	}
+	if args.FNT != "" || args.PNG != "" {
+		return 0
+	}
+	fmt.Printf("Charset at %04X\n", addr)
+	for code := 0; code < 128; code += 8 {
+		var glyphs [8][8]string
+		header := make([]string, 8)
+		for i := range glyphs {
+			glyphs[i] = video.GlyphText(font, byte(code+i), args.Multicolor)
+			header[i] = fmt.Sprintf("%-8s", fmt.Sprintf("$%02X", code+i))
+		}
+		fmt.Println()
+		fmt.Println(strings.TrimRight(strings.Join(header, " "), " "))
+		for y := 0; y < 8; y++ {
+			line := make([]string, 8)
+			for i := range glyphs {
+				line[i] = glyphs[i][y]
+			}
+			fmt.Println(strings.Join(line, " "))
+		}
+	}
+	return 0
+}
+
+func cmdDumpPMG(socket string) int {
+	layout, objects, err := rpcClient(socket).PlayerMissiles(context.Background())
+	if err != nil {
+		return fail(err)
+	}
+	resolution := "double-line"
+	if layout.SingleLine {
+		resolution = "single-line"
+	}
+	fmt.Printf("P/M area %04X-%04X, %s\n", layout.Base, int(layout.Base)+layout.Size()-1, resolution)
+	for _, obj := range objects {
+		fmt.Println()
+		fmt.Println(obj.Describe())
+		for _, line := range obj.Lines() {
+			fmt.Println("  " + line)
+		}
+	}
+	return 0
+}
+
+func writePNG(path string, img image.Image) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := png.Encode(f, img); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
 func dumpMemory(address uint16, length int, data []byte, raw bool, asJSON bool, useATASCII bool, columns int, columnsProvided bool, showHex bool, showASCII bool) int {
 	if columnsProvided && (raw || asJSON) {
 		fmt.Fprintln(os.Stderr, "--columns is only valid for formatted output")
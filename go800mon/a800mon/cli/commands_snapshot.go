@@ -0,0 +1,82 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+func cmdSnapshotSave(socket string, args cliSnapshotSaveCmd) int {
+	cl := rpcClient(socket)
+	ctx := context.Background()
+	ranges := []MemoryRange{{Addr: 0, Length: 0x10000}}
+	if len(args.Ranges) > 0 {
+		ranges = ranges[:0]
+		for _, text := range args.Ranges {
+			startExpr, endExpr, ok := strings.Cut(text, ":")
+			if !ok {
+				return fail(fmt.Errorf("Invalid range: %s (expected START:END).", text))
+			}
+			bounds, err := parseAddresses(ctx, cl, []string{startExpr, endExpr})
+			if err != nil {
+				return fail(err)
+			}
+			if bounds[1] < bounds[0] {
+				return fail(fmt.Errorf("Invalid range: %s (end before start).", text))
+			}
+			ranges = append(ranges, MemoryRange{Addr: bounds[0], Length: int(bounds[1]-bounds[0]) + 1})
+		}
+	}
+	snap, err := cl.CaptureSnapshot(ctx, args.Name, ranges)
+	if err != nil {
+		return fail(err)
+	}
+	path, err := snap.Save()
+	if err != nil {
+		return fail(err)
+	}
+	fmt.Printf("Saved %s (%d bytes).\n", path, snap.Size())
+	printSnapshotHeader(snap)
+	return 0
+}
+
+func cmdSnapshotDiff(socket string, args cliSnapshotDiffCmd) int {
+	a, err := LoadSnapshot(args.A)
+	if err != nil {
+		return fail(err)
+	}
+	var b MemorySnapshot
+	if args.B == "live" {
+		b, err = rpcClient(socket).CaptureSnapshot(context.Background(), "live", a.MemoryRanges())
+	} else {
+		b, err = LoadSnapshot(args.B)
+	}
+	if err != nil {
+		return fail(err)
+	}
+	printSnapshotHeader(a)
+	printSnapshotHeader(b)
+	fmt.Println()
+	changes := a.Diff(b)
+	// Consecutive changed bytes are printed as one run.
+	for i := 0; i < len(changes); {
+		j := i + 1
+		for j < len(changes) && changes[j].Addr == changes[j-1].Addr+1 && j-i < 16 {
+			j++
+		}
+		old := make([]byte, 0, j-i)
+		cur := make([]byte, 0, j-i)
+		for _, c := range changes[i:j] {
+			old = append(old, c.Old)
+			cur = append(cur, c.New)
+		}
+		fmt.Printf("%04X: %s -> %s\n", changes[i].Addr, fmtBytes(old), fmtBytes(cur))
+		i = j
+	}
+	fmt.Printf("%d bytes changed.\n", len(changes))
+	return 0
+}
+
+func printSnapshotHeader(snap MemorySnapshot) {
+	fmt.Printf("%s  %s  seq=%d  %s\n", snap.Name, snap.Time.Format("2006-01-02 15:04:05"), snap.StateSeq, formatCPU(snap.CPU))
+}
@@ -14,11 +14,13 @@ import (
 )
 
 func cmdTrainer(socket string, args cliTrainerCmd) int {
-	start, err := memory.ParseHex(args.Start)
+	cl := rpcClient(socket)
+	defer cl.Close()
+	start, err := cl.ParseAddress(context.Background(), args.Start)
 	if err != nil {
 		return fail(err)
 	}
-	stop, err := memory.ParseHex(args.Stop)
+	stop, err := cl.ParseAddress(context.Background(), args.Stop)
 	if err != nil {
 		return fail(err)
 	}
@@ -30,8 +32,6 @@ func cmdTrainer(socket string, args cliTrainerCmd) int {
 	if err != nil {
 		return fail(err)
 	}
-	cl := rpcClient(socket)
-	defer cl.Close()
 	trainer.BindReader(func(addr uint16, length int) ([]byte, error) {
 		return cl.ReadMemoryChunked(context.Background(), addr, length)
 	})
@@ -0,0 +1,109 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"go800mon/internal/binfile"
+	"go800mon/internal/disasm"
+	"go800mon/internal/memorymap"
+)
+
+// xexEntryBytes limits how much code after an entry point is disassembled.
+const xexEntryBytes = 48
+
+func cmdXexInfo(args cliXexInfoCmd) int {
+	data, err := os.ReadFile(args.File)
+	if err != nil {
+		return fail(err)
+	}
+	segs, err := binfile.ParseXEX(data)
+	if err != nil {
+		return fail(err)
+	}
+	entries := binfile.Entries(segs)
+	var warnings []string
+	fmt.Println("Segments:")
+	for i, seg := range segs {
+		var notes []string
+		if name := memorymap.Lookup(seg.Addr); name != "" {
+			notes = append(notes, name)
+		}
+		for _, area := range memorymap.AreasIn(seg.Addr, seg.End()) {
+			notes = append(notes, "["+area.Name+"]")
+			if w := xexAreaWarning(seg, area); w != "" {
+				warnings = append(warnings, fmt.Sprintf("#%d %04X-%04X %s", i+1, seg.Addr, seg.End(), w))
+			}
+		}
+		line := fmt.Sprintf("#%d %04X-%04X len=%04X %s", i+1, seg.Addr, seg.End(), len(seg.Data), strings.Join(notes, " "))
+		fmt.Println(strings.TrimSpace(line))
+		for _, e := range entries {
+			if e.Kind == "INIT" && e.Segment == i {
+				fmt.Printf("   INIT %s\n", xexEntryName(e.Addr))
+			}
+		}
+	}
+	if len(entries) == 0 || entries[len(entries)-1].Kind != "RUN" {
+		fmt.Println("No RUNAD: DOS returns to the menu unless an INIT routine takes over.")
+	} else {
+		fmt.Printf("RUN %s\n", xexEntryName(entries[len(entries)-1].Addr))
+	}
+	for _, o := range binfile.Overwrites(segs) {
+		warnings = append(warnings, fmt.Sprintf("#%d %04X-%04X is overwritten by #%d.", o.Segment+1, o.Start, o.End, o.By+1))
+	}
+	if len(warnings) > 0 {
+		fmt.Println()
+		fmt.Println("Warnings:")
+		for _, w := range warnings {
+			fmt.Println(w)
+		}
+	}
+	if !args.Disasm {
+		return 0
+	}
+	for _, e := range entries {
+		fmt.Println()
+		fmt.Printf("%s %s:\n", e.Kind, xexEntryName(e.Addr))
+		// Code is taken from memory as loaded when the entry is called.
+		mem, loaded := binfile.Image(segs[:e.Segment+1])
+		n := 0
+		for n < xexEntryBytes && loaded[e.Addr+uint16(n)] {
+			n++
+		}
+		if n == 0 {
+			fmt.Println("Entry point is not loaded by this file.")
+			continue
+		}
+		code := make([]byte, n)
+		for i := range code {
+			code[i] = mem[e.Addr+uint16(i)]
+		}
+		for _, line := range disasm.Disasm(e.Addr, code, false) {
+			fmt.Println(line)
+		}
+	}
+	return 0
+}
+
+func xexEntryName(addr uint16) string {
+	if name := memorymap.Lookup(addr); name != "" {
+		return fmt.Sprintf("%04X (%s)", addr, name)
+	}
+	return fmt.Sprintf("%04X", addr)
+}
+
+// xexAreaWarning explains what happens to seg's data in area; loading the
+// RUNAD and INITAD vectors into the OS variables is expected.
+func xexAreaWarning(seg binfile.Segment, area memorymap.Area) string {
+	switch area.Kind {
+	case memorymap.AreaIO:
+		return fmt.Sprintf("writes %s registers; the data never reaches RAM.", area.Name)
+	case memorymap.AreaROM:
+		return fmt.Sprintf("loads under %s; it is only visible with the ROM disabled.", area.Name)
+	}
+	if seg.Addr >= binfile.RUNAD && seg.End() <= binfile.INITAD+1 {
+		return ""
+	}
+	return fmt.Sprintf("overwrites %s.", area.Name)
+}
@@ -6,6 +6,8 @@ import (
 	"strings"
 
 	"github.com/alecthomas/kong"
+
+	"go800mon/internal/memorymap"
 )
 
 func Main(argv []string) int {
@@ -23,6 +25,11 @@ func Main(argv []string) int {
 		return 2
 	}
 	socket := args.Socket
+	if len(args.Symbols) > 0 {
+		if _, err := memorymap.LoadSymbolFiles(args.Symbols); err != nil {
+			return fail(err)
+		}
+	}
 	selected := parsed.Selected()
 	if selected == nil {
 		return cmdMonitor(socket)
@@ -74,6 +81,10 @@ func Main(argv []string) int {
 		return cmdPIAState(socket)
 	case "dump pokey":
 		return cmdPOKEYState(socket)
+	case "dump charset":
+		return cmdDumpCharset(socket, args.Dump.Charset)
+	case "dump pmg":
+		return cmdDumpPMG(socket)
 	case "cpu", "cpu get":
 		return cmdCPUState(socket)
 	case "cpu set":
@@ -88,16 +99,50 @@ func Main(argv []string) int {
 		return cmdSearch(socket, args.Mem.Search)
 	case "mem disasm":
 		return cmdDisasm(socket, args.Mem.Disasm)
+	case "mem asm":
+		return cmdAsm(socket, args.Mem.Asm)
+	case "mem xref":
+		return cmdXref(socket, args.Mem.Xref)
+	case "mem export-asm":
+		return cmdExportAsm(socket, args.Mem.Export)
+	case "mem save":
+		return cmdMemSave(socket, args.Mem.Save)
+	case "mem load":
+		return cmdMemLoad(socket, args.Mem.Load)
+	case "mem fill":
+		return cmdMemFill(socket, args.Mem.Fill)
+	case "mem copy":
+		return cmdMemCopy(socket, args.Mem.Copy)
+	case "mem compare":
+		return cmdMemCompare(socket, args.Mem.Compare)
+	case "mem checksum":
+		return cmdMemChecksum(socket, args.Mem.Checksum)
+	case "mem pointers":
+		return cmdMemPointers(socket, args.Mem.Pointers)
+	case "mem snapshot save":
+		return cmdSnapshotSave(socket, args.Mem.Snapshot.Save)
+	case "mem snapshot diff":
+		return cmdSnapshotDiff(socket, args.Mem.Snapshot.Diff)
 	case "rpc ping":
 		return cmdPing(socket)
 	case "cart", "cart status":
 		return cmdCartState(socket)
+	case "cart info":
+		return cmdCartInfo(args.Cart.Info)
 	case "cart remove":
 		return cmdCartRemove(socket)
 	case "tape remove":
 		return cmdTapeRemove(socket)
 	case "disk remove":
 		return cmdDiskRemove(socket, args.Disk.Remove)
+	case "xex info":
+		return cmdXexInfo(args.XEX.Info)
+	case "atr info":
+		return cmdAtrInfo(args.ATR.Info)
+	case "atr ls":
+		return cmdAtrList(args.ATR.LS)
+	case "atr extract":
+		return cmdAtrExtract(args.ATR.Extract)
 	case "trainer":
 		return cmdTrainer(socket, args.Trainer)
 	case "screen":
@@ -141,6 +186,7 @@ func parseCLI(argv []string) (cliArgs, *kong.Context, error) {
 		kong.Name("go800mon"),
 		kong.Description("Atari800 monitor UI and CLI."),
 		kong.UsageOnError(),
+		kong.Vars{"addr": addrHelp},
 		kong.ConfigureHelp(kong.HelpOptions{
 			Compact:   true,
 			FlagsLast: true,
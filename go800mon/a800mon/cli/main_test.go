@@ -0,0 +1,137 @@
+package cli
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	"go800mon/internal/rpc/rpctest"
+)
+
+func newTestServer(t *testing.T) *rpctest.Server {
+	t.Helper()
+	srv, err := rpctest.NewServer("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = srv.Close() })
+	return srv
+}
+
+// runMain runs cli.Main against srv and returns the exit code and stdout.
+func runMain(t *testing.T, srv *rpctest.Server, args ...string) (int, string) {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	out := make(chan string)
+	go func() {
+		var buf bytes.Buffer
+		_, _ = io.Copy(&buf, r)
+		out <- buf.String()
+	}()
+	code := Main(append([]string{"--socket", srv.Path()}, args...))
+	os.Stdout = stdout
+	_ = w.Close()
+	return code, <-out
+}
+
+func TestMainPing(t *testing.T) {
+	srv := newTestServer(t)
+	if code, _ := runMain(t, srv, "rpc", "ping"); code != 0 {
+		t.Fatalf("rpc ping exit code %d", code)
+	}
+}
+
+func TestMainMemFillAndRead(t *testing.T) {
+	srv := newTestServer(t)
+	if code, out := runMain(t, srv, "mem", "fill", "$2000", "$2007", "AA", "55"); code != 0 {
+		t.Fatalf("mem fill exit code %d: %s", code, out)
+	}
+	st := srv.State()
+	want := []byte{0xAA, 0x55, 0xAA, 0x55, 0xAA, 0x55, 0xAA, 0x55}
+	if got := st.Memory[0x2000:0x2008]; !bytes.Equal(got, want) {
+		t.Fatalf("memory after fill = % X, want % X", got, want)
+	}
+	code, out := runMain(t, srv, "mem", "read", "$2000", "8")
+	if code != 0 {
+		t.Fatalf("mem read exit code %d", code)
+	}
+	if !strings.Contains(out, "AA 55 AA 55") {
+		t.Fatalf("mem read output %q lacks filled bytes", out)
+	}
+}
+
+func TestMainDisasm(t *testing.T) {
+	srv := newTestServer(t)
+	srv.LoadMemory(0x0600, []byte{0xA9, 0x01, 0x8D, 0x00, 0xD0, 0x60})
+	code, out := runMain(t, srv, "mem", "disasm", "$0600", "6")
+	if code != 0 {
+		t.Fatalf("mem disasm exit code %d", code)
+	}
+	for _, want := range []string{"LDA #$01", "STA", "RTS"} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("mem disasm output %q lacks %q", out, want)
+		}
+	}
+}
+
+func TestMainXref(t *testing.T) {
+	srv := newTestServer(t)
+	// LDA $2000 / RTS followed by data that decodes as another LDA $2000.
+	srv.LoadMemory(0x0600, []byte{0xAD, 0x00, 0x20, 0x60, 0xAD, 0x00, 0x20})
+	srv.Update(func(st *rpctest.State) { st.CPU.PC = 0x0600 })
+	for _, tt := range []struct {
+		args []string
+		want []string
+	}{
+		{args: nil, want: []string{"0600:", "0604:"}},
+		{args: []string{"--flow"}, want: []string{"0600:"}},
+	} {
+		args := append([]string{"mem", "xref", "$2000", "--range", "$0600,$06FF"}, tt.args...)
+		code, out := runMain(t, srv, args...)
+		if code != 0 {
+			t.Fatalf("mem xref %v exit code %d", tt.args, code)
+		}
+		lines := strings.Split(strings.TrimSpace(out), "\n")
+		if len(lines) != len(tt.want) {
+			t.Fatalf("mem xref %v output %q, want references at %v", tt.args, out, tt.want)
+		}
+		for i, want := range tt.want {
+			if !strings.Contains(lines[i], want) {
+				t.Fatalf("mem xref %v line %d = %q, want %s", tt.args, i, lines[i], want)
+			}
+		}
+	}
+}
+
+func TestMainMemFillWholeMemory(t *testing.T) {
+	srv := newTestServer(t)
+	if code, out := runMain(t, srv, "mem", "fill", "0000", "FFFF", "AA"); code != 0 {
+		t.Fatalf("mem fill exit code %d: %s", code, out)
+	}
+	st := srv.State()
+	for addr, b := range st.Memory {
+		if b != 0xAA {
+			t.Fatalf("memory[%04X] = %02X after full fill", addr, b)
+		}
+	}
+}
+
+func TestMainSearchWildcardsUseClient(t *testing.T) {
+	srv := newTestServer(t)
+	srv.LoadMemory(0x0600, []byte{0xA9, 0x0E, 0x8D, 0x0A, 0xD4, 0xA9, 0x00, 0x8D, 0x1A, 0xD0})
+	code, out := runMain(t, srv, "mem", "search", "0600", "06FF", "A9", "??", "8D", "??", "D4")
+	if code != 0 {
+		t.Fatalf("mem search exit code %d: %s", code, out)
+	}
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	if len(lines) != 2 || lines[0] != "matches=1" || !strings.HasPrefix(lines[1], "0600") {
+		t.Fatalf("mem search output %q, want one match at $0600", out)
+	}
+}
@@ -2,8 +2,13 @@ package cli
 
 import "regexp"
 
+// addrHelp describes the address expression syntax; help tags refer to it as
+// ${addr}.
+const addrHelp = "(hex: $NNNN, symbol, name+offset, #dec, pc, s+$101, [vector])"
+
 type cliArgs struct {
 	Socket   string            `short:"s" default:"/tmp/atari.sock" help:"Path to Atari800 monitor socket."`
+	Symbols  []string          `name:"symbols" type:"existingfile" help:"Program symbol file (MADS/xasm .lab, ca65 .lbl/.dbg). Repeatable."`
 	Monitor  cliEmptyCmd       `cmd:"" help:"Run the curses monitor UI."`
 	Run      cliRunCmd         `cmd:"" help:"Run a file via RPC."`
 	Debug    cliDebugCmd       `cmd:"" aliases:"d" help:"Debugger commands."`
@@ -18,6 +23,8 @@ type cliArgs struct {
 	Disk     cliDiskCmd        `cmd:"" name:"disk" help:"Disk commands."`
 	Screen   cliScreenCmd      `cmd:"" help:"Dump screen memory segments."`
 	Trainer  cliTrainerCmd     `cmd:"" name:"trainer" help:"Interactive value trainer."`
+	XEX      cliXexCmd         `cmd:"" name:"xex" help:"Inspect Atari executables offline."`
+	ATR      cliAtrCmd         `cmd:"" name:"atr" help:"Inspect ATR disk images and extract files."`
 }
 
 type cliEmptyCmd struct{}
@@ -76,25 +83,127 @@ type cliBLineCmd struct {
 	Scanline *string `arg:"" optional:"" help:"Optional scanline (hex: 0xNNNN, $NNNN, NNNN)."`
 }
 
+type cliXexCmd struct {
+	Info cliXexInfoCmd `cmd:"" help:"List segments, INIT/RUN vectors and memory conflicts of an XEX file."`
+}
+
+type cliXexInfoCmd struct {
+	File   string `arg:"" type:"existingfile" help:"XEX file."`
+	Disasm bool   `short:"d" name:"disasm" help:"Disassemble the INIT and RUN entry code."`
+}
+
+type cliAtrCmd struct {
+	Info    cliAtrInfoCmd    `cmd:"" help:"Show geometry, the boot record and the VTOC."`
+	LS      cliAtrFileCmd    `cmd:"" name:"ls" help:"List DOS 2.x/MyDOS directory entries."`
+	Extract cliAtrExtractCmd `cmd:"" help:"Copy files from the image to the host."`
+}
+
+type cliAtrInfoCmd struct {
+	File   string `arg:"" type:"existingfile" help:"ATR image."`
+	Disasm bool   `short:"d" name:"disasm" help:"Disassemble the boot sector code."`
+}
+
+type cliAtrFileCmd struct {
+	File string `arg:"" type:"existingfile" help:"ATR image."`
+}
+
+type cliAtrExtractCmd struct {
+	File   string   `arg:"" type:"existingfile" help:"ATR image."`
+	Names  []string `arg:"" optional:"" help:"Files to extract, e.g. AUTORUN.SYS or DIR>FILE.EXT. Default: all."`
+	Output string   `short:"o" name:"output" type:"path" default:"." help:"Output directory."`
+}
+
 type cliTrainerCmd struct {
-	Start string `arg:"" help:"Start address (hex: 0xNNNN, $NNNN, NNNN)."`
-	Stop  string `arg:"" help:"Stop address (hex: 0xNNNN, $NNNN, NNNN)."`
+	Start string `arg:"" help:"Start address ${addr}."`
+	Stop  string `arg:"" help:"Stop address ${addr}."`
 	Value string `arg:"" help:"Initial byte value (hex: 00..FF)."`
 }
 
 type cliMemCmd struct {
-	Read   cliReadMemCmd  `cmd:"" aliases:"r" help:"Read memory."`
-	Write  cliWriteMemCmd `cmd:"" aliases:"w" help:"Write memory."`
-	Search cliSearchCmd   `cmd:"" aliases:"s" help:"Search memory for a pattern."`
-	Disasm cliDisasmCmd   `cmd:"" aliases:"d" help:"Disassemble 6502 memory."`
+	Read     cliReadMemCmd     `cmd:"" aliases:"r" help:"Read memory."`
+	Write    cliWriteMemCmd    `cmd:"" aliases:"w" help:"Write memory."`
+	Search   cliSearchCmd      `cmd:"" aliases:"s" help:"Search memory for a pattern."`
+	Disasm   cliDisasmCmd      `cmd:"" aliases:"d" help:"Disassemble 6502 memory."`
+	Asm      cliAsmCmd         `cmd:"" aliases:"a" help:"Assemble 6502 source into memory."`
+	Xref     cliXrefCmd        `cmd:"" aliases:"x" help:"List instructions that call, jump to, read or write an address."`
+	Export   cliExportAsmCmd   `cmd:"" name:"export-asm" help:"Export a memory range as reassemblable source."`
+	Snapshot cliSnapshotCmd    `cmd:"" name:"snapshot" help:"Save memory snapshots and compare them."`
+	Save     cliMemSaveCmd     `cmd:"" help:"Save a memory range to a binary, XEX or Intel HEX file."`
+	Load     cliMemLoadCmd     `cmd:"" help:"Load a binary, XEX or Intel HEX file into memory."`
+	Fill     cliMemFillCmd     `cmd:"" help:"Fill a memory range with a repeated byte pattern."`
+	Copy     cliMemCopyCmd     `cmd:"" help:"Copy a memory range; overlapping ranges are safe."`
+	Compare  cliMemCompareCmd  `cmd:"" help:"List offsets where two memory blocks differ."`
+	Checksum cliMemChecksumCmd `cmd:"" help:"Compute a checksum over a memory range."`
+	Pointers cliMemPointersCmd `cmd:"" help:"Find pointer chains to an address and rank them over snapshots."`
+}
+
+type cliMemFillCmd struct {
+	Start string   `arg:"" help:"Start address ${addr}."`
+	End   string   `arg:"" help:"End address, inclusive ${addr}."`
+	Bytes []string `arg:"" help:"Pattern byte/word values (hex). Values > FF are little-endian words."`
+}
+
+type cliMemCopyCmd struct {
+	Src string `arg:"" help:"Source start address ${addr}."`
+	End string `arg:"" help:"Source end address, inclusive ${addr}."`
+	Dst string `arg:"" help:"Destination address ${addr}."`
+}
+
+type cliMemCompareCmd struct {
+	A      string `arg:"" help:"First block address ${addr}."`
+	B      string `arg:"" help:"Second block address ${addr}."`
+	Length string `arg:"" help:"Length (hex: 0xNNNN, $NNNN, NNNN)."`
+}
+
+type cliMemChecksumCmd struct {
+	Start string `arg:"" help:"Start address ${addr}."`
+	End   string `arg:"" help:"End address, inclusive ${addr}."`
+	Algo  string `name:"algo" enum:"crc16,crc32,sum8" default:"crc16" help:"Algorithm: crc16 (XMODEM), crc32 (IEEE) or sum8."`
+}
+
+type cliMemPointersCmd struct {
+	Target    string `arg:"" help:"Target address ${addr}."`
+	Depth     int    `name:"depth" default:"1" help:"Maximum number of pointers in a chain."`
+	MaxOffset string `name:"max-offset" default:"$20" help:"Largest distance between a pointer and the address it leads to (hex: $NN or #dec)."`
+}
+
+type cliMemSaveCmd struct {
+	Start  string `arg:"" help:"Start address ${addr}."`
+	End    string `arg:"" help:"End address, inclusive ${addr}."`
+	File   string `arg:"" type:"path" help:"Output file."`
+	Format string `short:"f" name:"format" enum:"bin,xex,ihex" default:"bin" help:"File format: bin, xex or ihex."`
+}
+
+type cliMemLoadCmd struct {
+	File string  `arg:"" type:"existingfile" help:"XEX, Intel HEX (.hex) or raw binary file."`
+	Addr *string `arg:"" optional:"" help:"Load address of a raw binary file ${addr}."`
+	Run  bool    `short:"r" name:"run" help:"Set PC to the RUNAD of an XEX file after loading."`
+}
+
+type cliSnapshotCmd struct {
+	Save cliSnapshotSaveCmd `cmd:"" help:"Capture memory with the CPU state into a named snapshot."`
+	Diff cliSnapshotDiffCmd `cmd:"" help:"List bytes that differ between two snapshots."`
+}
+
+type cliSnapshotSaveCmd struct {
+	Name   string   `arg:"" help:"Snapshot name, or a path to a .json file."`
+	Ranges []string `arg:"" optional:"" help:"Ranges as START:END, inclusive ${addr}. Default: all 64K."`
+}
+
+type cliSnapshotDiffCmd struct {
+	A string `arg:"" help:"Snapshot name or path."`
+	B string `arg:"" help:"Snapshot name or path, or live to compare with current memory."`
 }
 
 type cliSearchCmd struct {
 	ATASCII      bool     `short:"a" name:"atascii" help:"Convert input text to ATASCII bytes before search."`
 	SearchScreen bool     `name:"screen" help:"Convert input text to screen-codes before search."`
-	Start        string   `arg:"" help:"Start address (hex: 0xNNNN, $NNNN, NNNN)."`
-	End          string   `arg:"" help:"End address (hex: 0xNNNN, $NNNN, NNNN)."`
-	Pattern      []string `arg:"" help:"Hex bytes by default; text when --atascii and/or --screen is used."`
+	Start        string   `arg:"" help:"Start address ${addr}."`
+	End          string   `arg:"" help:"End address ${addr}."`
+	Pattern      []string `arg:"" help:"Hex bytes by default; text when --atascii and/or --screen is used. With --client also ?? and A? wildcards, (A9|AD) alternatives, w:1234 words and \"TEXT\"."`
+	Client       bool     `short:"c" name:"client" help:"Read the range and match it here, every match reported with its symbol. Used automatically for wildcards, nibble masks, alternatives and words."`
+	IgnoreCase   bool     `short:"i" name:"ignore-case" help:"Match text letters in either case (implies --client)."`
+	Bank         *int     `name:"bank" help:"Search extended RAM bank N at $4000-$7FFF (emulator must be paused)."`
 }
 
 type cliCpuCmd struct {
@@ -104,11 +213,13 @@ type cliCpuCmd struct {
 }
 
 type cliDumpCmd struct {
-	DList cliDListCmd `cmd:"" name:"dlist" help:"Dump display list."`
-	GTIA  cliEmptyCmd `cmd:"" name:"gtia" help:"Show GTIA register state."`
-	ANTIC cliEmptyCmd `cmd:"" name:"antic" help:"Show ANTIC register state."`
-	PIA   cliEmptyCmd `cmd:"" name:"pia" help:"Show PIA register state."`
-	POKEY cliEmptyCmd `cmd:"" name:"pokey" help:"Show POKEY register state."`
+	DList   cliDListCmd   `cmd:"" name:"dlist" help:"Dump display list."`
+	GTIA    cliEmptyCmd   `cmd:"" name:"gtia" help:"Show GTIA register state."`
+	ANTIC   cliEmptyCmd   `cmd:"" name:"antic" help:"Show ANTIC register state."`
+	PIA     cliEmptyCmd   `cmd:"" name:"pia" help:"Show PIA register state."`
+	POKEY   cliEmptyCmd   `cmd:"" name:"pokey" help:"Show POKEY register state."`
+	Charset cliCharsetCmd `cmd:"" name:"charset" help:"Show or export a character set."`
+	PMG     cliEmptyCmd   `cmd:"" name:"pmg" help:"Draw players and missiles from the PMBASE area."`
 }
 
 type cliRpcCmd struct {
@@ -116,8 +227,13 @@ type cliRpcCmd struct {
 }
 
 type cliCartCmd struct {
-	Status cliEmptyCmd `cmd:"" default:"1" help:"Show cartridge state."`
-	Remove cliEmptyCmd `cmd:"" help:"Remove cartridge."`
+	Status cliEmptyCmd    `cmd:"" default:"1" help:"Show cartridge state."`
+	Remove cliEmptyCmd    `cmd:"" help:"Remove cartridge."`
+	Info   cliCartInfoCmd `cmd:"" help:"Decode a CAR image header and verify its checksum."`
+}
+
+type cliCartInfoCmd struct {
+	File string `arg:"" type:"existingfile" help:"CAR image."`
 }
 
 type cliTapeCmd struct {
@@ -135,15 +251,24 @@ type cliDiskRemoveCmd struct {
 
 type cliSetRegCmd struct {
 	Target string `arg:"" enum:"pc,a,x,y,s,n,v,d,i,z,c" help:"Target register/flag."`
-	Value  string `arg:"" help:"Value (hex: 0xNNNN, $NNNN, NNNN)."`
+	Value  string `arg:"" help:"Value ${addr}."`
 }
 
 type cliDListCmd struct {
-	Address *string `arg:"" optional:"" help:"Optional display list start address (hex: 0xNNNN, $NNNN, NNNN)."`
+	Address   *string `arg:"" optional:"" help:"Optional display list start address ${addr}."`
+	Lint      bool    `short:"l" name:"lint" help:"Check for ANTIC pitfalls: 4K/1K crossings, missing JVB, too many scanlines, scroll and DLI mistakes."`
+	Scanlines bool    `name:"scanlines" help:"List every scanline with its entry, DLI and estimated DMA cycles."`
+}
+
+type cliCharsetCmd struct {
+	Address    *string `arg:"" optional:"" help:"Character set address, default CHBASE ${addr}."`
+	Multicolor bool    `short:"m" name:"multicolor" help:"Interpret glyphs as 4-color ANTIC 4/5 characters."`
+	PNG        string  `name:"png" type:"path" help:"Write the glyphs to a PNG file using the live playfield colors."`
+	FNT        string  `name:"fnt" type:"path" help:"Write the raw 1024-byte font to a file."`
 }
 
 type cliReadMemCmd struct {
-	Addr    string `arg:"" help:"Address (hex: 0xNNNN, $NNNN, NNNN)."`
+	Addr    string `arg:"" help:"Address ${addr}."`
 	Length  string `arg:"" help:"Length (hex: 0xNNNN, $NNNN, NNNN)."`
 	Raw     bool   `name:"raw" xor:"format" help:"Output raw bytes without formatting."`
 	JSON    bool   `name:"json" xor:"format" help:"Output JSON with address and buffer."`
@@ -151,10 +276,11 @@ type cliReadMemCmd struct {
 	Columns *int   `short:"c" name:"columns" help:"Bytes per line (default: 16)."`
 	NoHex   bool   `name:"nohex" help:"Hide hex column in formatted output."`
 	NoASCII bool   `name:"noascii" help:"Hide ASCII column in formatted output."`
+	Bank    *int   `name:"bank" help:"Read extended RAM bank N at $4000-$7FFF (emulator must be paused)."`
 }
 
 type cliWriteMemCmd struct {
-	Addr    string   `arg:"" help:"Address (hex: 0xNNNN, $NNNN, NNNN)."`
+	Addr    string   `arg:"" help:"Address ${addr}."`
 	Bytes   []string `arg:"" optional:"" help:"Byte/word values (hex). Values > FF are written as little-endian words."`
 	Hex     *string  `name:"hex" help:"Hex payload (001122...) or '-' to read from stdin."`
 	Text    *string  `name:"text" help:"Text payload or '-' to read from stdin."`
@@ -163,19 +289,46 @@ type cliWriteMemCmd struct {
 }
 
 type cliDisasmCmd struct {
-	Addr   string `arg:"" help:"Address (hex: 0xNNNN, $NNNN, NNNN)."`
-	Length string `arg:"" help:"Length (hex: 0xNNNN, $NNNN, NNNN)."`
+	Addr    string   `arg:"" help:"Address ${addr}."`
+	Length  string   `arg:"" help:"Length (hex: 0xNNNN, $NNNN, NNNN)."`
+	Illegal bool     `short:"i" name:"illegal" help:"Decode undocumented 6502 opcodes."`
+	Flow    bool     `short:"f" name:"flow" help:"Follow control flow from vectors, PC and jump history; list unreached bytes as .BYTE."`
+	Entry   []string `short:"e" name:"entry" help:"Extra code entry point for --flow (address expression). Repeatable; implies --flow."`
+	Cycles  bool     `short:"c" name:"cycles" help:"Show cycle counts and total them over the range."`
+	Bank    *int     `name:"bank" help:"Read extended RAM bank N at $4000-$7FFF (emulator must be paused)."`
+}
+
+type cliXrefCmd struct {
+	Addr    string   `arg:"" help:"Target address ${addr}."`
+	Range   []string `name:"range" help:"Range to scan as START,END (default: whole memory)."`
+	Illegal bool     `short:"i" name:"illegal" help:"Decode undocumented 6502 opcodes."`
+	Flow    bool     `short:"f" name:"flow" help:"Only count instructions reached by control flow from vectors, PC and jump history."`
+	Entry   []string `short:"e" name:"entry" help:"Extra code entry point for --flow (address expression). Repeatable; implies --flow."`
+}
+
+type cliExportAsmCmd struct {
+	Start   string   `arg:"" help:"Start address ${addr}."`
+	End     string   `arg:"" help:"End address, inclusive ${addr}."`
+	Format  string   `name:"format" enum:"mads,ca65,xasm" default:"mads" help:"Assembler syntax: mads, ca65 or xasm."`
+	Illegal bool     `short:"i" name:"illegal" help:"Follow code through undocumented 6502 opcodes; they are exported as bytes."`
+	Entry   []string `short:"e" name:"entry" help:"Extra code entry point (address expression). Repeatable; start is always one."`
+}
+
+type cliAsmCmd struct {
+	File    string `arg:"" help:"Source file or '-' to read from stdin."`
+	Illegal bool   `short:"i" name:"illegal" help:"Accept undocumented 6502 opcodes."`
 }
 
 type cliScreenCmd struct {
-	Segment *int `arg:"" optional:"" help:"Segment number (1-based). When omitted, dumps all segments."`
-	List    bool `short:"l" name:"list" help:"List screen segments."`
-	Raw     bool `name:"raw" xor:"format" help:"Output raw bytes without formatting."`
-	JSON    bool `name:"json" xor:"format" help:"Output JSON with address and buffer."`
-	ATASCII bool `short:"a" name:"atascii" help:"Render ASCII column using ATASCII mapping."`
-	Columns *int `short:"c" name:"columns" help:"Bytes per line (default: 16)."`
-	NoHex   bool `name:"nohex" help:"Hide hex column in formatted output."`
-	NoASCII bool `name:"noascii" help:"Hide ASCII column in formatted output."`
+	Segment *int   `arg:"" optional:"" help:"Segment number (1-based). When omitted, dumps all segments."`
+	List    bool   `short:"l" name:"list" help:"List screen segments."`
+	Raw     bool   `name:"raw" xor:"format" help:"Output raw bytes without formatting."`
+	JSON    bool   `name:"json" xor:"format" help:"Output JSON with address and buffer."`
+	ATASCII bool   `short:"a" name:"atascii" help:"Render ASCII column using ATASCII mapping."`
+	Columns *int   `short:"c" name:"columns" help:"Bytes per line (default: 16)."`
+	NoHex   bool   `name:"nohex" help:"Hide hex column in formatted output."`
+	NoASCII bool   `name:"noascii" help:"Hide ASCII column in formatted output."`
+	PNG     string `name:"png" type:"path" help:"Render the screen from the display list and GTIA colors to a PNG file."`
 }
 
 var cliPathAliasPattern = regexp.MustCompile(`\s*\([^)]*\)`)
@@ -0,0 +1,28 @@
+package a800mon
+
+import (
+	"context"
+
+	"go800mon/internal/flow"
+)
+
+// AnalyzeFlow reads the whole address space and classifies it as code or data,
+// starting from the CPU vectors, the current PC, the jump history and marks.
+// It also returns the 64K of memory it analysed.
+func (r *RpcClient) AnalyzeFlow(ctx context.Context, marks []uint16, illegal bool) (*flow.Map, []byte, error) {
+	chunks, err := r.ReadMemoryV(ctx, []MemoryRange{{Addr: 0, Length: 0x10000}})
+	if err != nil {
+		return nil, nil, err
+	}
+	mem := chunks[0]
+	cpu, err := r.CPUState(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	entries := append(flow.VectorEntries(mem), cpu.PC)
+	if jumps, err := r.Jumps(ctx); err == nil {
+		entries = append(entries, jumps.PCs...)
+	}
+	entries = append(entries, marks...)
+	return flow.Analyze(mem, entries, illegal), mem, nil
+}
@@ -2,9 +2,11 @@ package monitor
 
 import (
 	"context"
+	"fmt"
 
 	. "go800mon/a800mon"
 	"go800mon/internal/displaylist"
+	"go800mon/internal/memorymap"
 )
 
 type Action int
@@ -22,9 +24,12 @@ const (
 	ActionWarmStart
 	ActionTerminate
 	ActionToggleFreeze
+	ActionReloadSymbols
 	ActionSetATASCII
+	ActionSetIllegalOpcodes
 	ActionSetDisassembly
 	ActionSetDisassemblyAddr
+	ActionSetXrefsAddr
 	ActionSetBreakpointsSupported
 	ActionSetStatus
 	ActionSetLastRPCError
@@ -34,6 +39,8 @@ const (
 	ActionSetDList
 	ActionSetDMACTL
 	ActionSetFrameTimeMS
+	ActionSetCart
+	ActionSetPORTB
 	ActionSetInputFocus
 	ActionQuit
 )
@@ -144,12 +151,29 @@ func (d *ActionDispatcher) Dispatch(action Action, value any) error {
 		return d.Dispatch(ActionExitShutdown, nil)
 	case ActionToggleFreeze:
 		store.setUIFrozen(!st.UIFrozen)
+	case ActionReloadSymbols:
+		// A failed reload keeps the previously loaded symbols.
+		n, err := memorymap.ReloadSymbolFiles()
+		if err != nil {
+			store.setNotice("Symbols: "+err.Error(), true)
+			return nil
+		}
+		store.setNotice(fmt.Sprintf("Reloaded %d symbols", n), false)
+		store.bumpSymbolsSeq()
+		// Refresh the CPU line so it picks up the new labels.
+		d.rpcFlushed = true
 	case ActionSetATASCII:
 		v := false
 		if b, ok := value.(bool); ok {
 			v = b
 		}
 		store.setUseATASCII(v)
+	case ActionSetIllegalOpcodes:
+		v := false
+		if b, ok := value.(bool); ok {
+			v = b
+		}
+		store.setIllegalOpcodes(v)
 	case ActionSetDisassembly:
 		v := false
 		if b, ok := value.(bool); ok {
@@ -160,6 +184,12 @@ func (d *ActionDispatcher) Dispatch(action Action, value any) error {
 		if v, ok := value.(uint16); ok {
 			store.setDisassemblyAddr(&v)
 		}
+	case ActionSetXrefsAddr:
+		if v, ok := value.(uint16); ok {
+			store.setXrefsAddr(&v)
+		} else {
+			store.setXrefsAddr(nil)
+		}
 	case ActionSetBreakpointsSupported:
 		v := false
 		if b, ok := value.(bool); ok {
@@ -174,6 +204,7 @@ func (d *ActionDispatcher) Dispatch(action Action, value any) error {
 				status.ResetMS,
 				status.Crashed,
 				status.StateSeq,
+				status.MachineType,
 			)
 		}
 	case ActionSetLastRPCError:
@@ -206,6 +237,14 @@ func (d *ActionDispatcher) Dispatch(action Action, value any) error {
 		if ms, ok := value.(int); ok {
 			store.setFrameTimeMS(ms)
 		}
+	case ActionSetCart:
+		if cart, ok := value.(CartState); ok {
+			store.setCart(cart)
+		}
+	case ActionSetPORTB:
+		if portb, ok := value.(byte); ok {
+			store.setPORTB(portb)
+		}
 	case ActionSetInputFocus:
 		if value == nil {
 			d.setInputFocus(nil)
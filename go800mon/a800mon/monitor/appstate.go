@@ -2,6 +2,7 @@ package monitor
 
 import (
 	"sync"
+	"time"
 
 	. "go800mon/a800mon"
 	"go800mon/internal/displaylist"
@@ -25,13 +26,22 @@ type AppStateData struct {
 	ResetMS              uint64
 	Crashed              bool
 	StateSeq             uint64
+	MachineType          byte
+	PORTB                byte
 	LastRPCError         string
+	Notice               string
+	NoticeError          bool
+	NoticeAt             time.Time
+	SymbolsSeq           uint64
 	ActiveMode           AppMode
 	UIFrozen             bool
 	UseATASCII           bool
+	IllegalOpcodes       bool
 	DisassemblyEnabled   bool
 	DisassemblyAddr      *uint16
+	XrefsAddr            *uint16
 	DMACTL               byte
+	Cart                 CartState
 	History              []CpuHistoryEntry
 	DisassemblyRows      []DisasmRow
 	BreakpointsSupported bool
@@ -48,6 +58,7 @@ type DisasmRow struct {
 	OperandAddrPos [2]int
 	HasOperandAddr bool
 	FlowTarget     *uint16
+	Cycles         string
 }
 
 type ScreenRow struct {
@@ -111,7 +122,7 @@ func (s *StateStore) snapshotLocked() AppStateData {
 	return st
 }
 
-func (s *StateStore) setStatus(paused bool, emuMS, resetMS uint64, crashed bool, stateSeq uint64) {
+func (s *StateStore) setStatus(paused bool, emuMS, resetMS uint64, crashed bool, stateSeq uint64, machineType byte) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 	s.s.Paused = paused
@@ -119,6 +130,13 @@ func (s *StateStore) setStatus(paused bool, emuMS, resetMS uint64, crashed bool,
 	s.s.ResetMS = resetMS
 	s.s.Crashed = crashed
 	s.s.StateSeq = stateSeq
+	s.s.MachineType = machineType
+}
+
+func (s *StateStore) setPORTB(portb byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.s.PORTB = portb
 }
 
 func (s *StateStore) setLastRPCError(text string) {
@@ -127,6 +145,20 @@ func (s *StateStore) setLastRPCError(text string) {
 	s.s.LastRPCError = text
 }
 
+func (s *StateStore) setNotice(text string, isError bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.s.Notice = text
+	s.s.NoticeError = isError
+	s.s.NoticeAt = time.Now()
+}
+
+func (s *StateStore) bumpSymbolsSeq() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.s.SymbolsSeq++
+}
+
 func (s *StateStore) setCPU(cpu CPUState, cpuDisasm string) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -141,6 +173,12 @@ func (s *StateStore) setDList(dl displaylist.DisplayList, dmactl byte) {
 	s.s.DMACTL = dmactl
 }
 
+func (s *StateStore) setCart(cart CartState) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.s.Cart = cart
+}
+
 func (s *StateStore) setHistory(rows []CpuHistoryEntry) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -169,6 +207,12 @@ func (s *StateStore) setUseATASCII(enabled bool) {
 	s.s.UseATASCII = enabled
 }
 
+func (s *StateStore) setIllegalOpcodes(enabled bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.s.IllegalOpcodes = enabled
+}
+
 func (s *StateStore) setDisassemblyEnabled(enabled bool) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -181,6 +225,12 @@ func (s *StateStore) setDisassemblyAddr(addr *uint16) {
 	s.s.DisassemblyAddr = addr
 }
 
+func (s *StateStore) setXrefsAddr(addr *uint16) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.s.XrefsAddr = addr
+}
+
 func (s *StateStore) setActiveMode(mode AppMode) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
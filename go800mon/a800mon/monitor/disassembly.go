@@ -7,7 +7,7 @@ import (
 
 	. "go800mon/a800mon"
 	"go800mon/internal/disasm"
-	"go800mon/internal/memory"
+	"go800mon/internal/flow"
 )
 
 type DisassemblyViewer struct {
@@ -27,6 +27,7 @@ type DisassemblyViewer struct {
 	hasCurrentAddr     bool
 	lastSnapshot       string
 	pendingNav         navAction
+	pendingAddrExpr    string
 	pendingSteps       int
 	pendingWriteAddr   uint16
 	pendingWriteData   []byte
@@ -36,6 +37,14 @@ type DisassemblyViewer struct {
 	editSnapshot       string
 	editText           string
 	editBytes          []byte
+	flowEnabled        bool
+	codeFlow           *flow.Map
+	flowStale          bool
+	flowSeq            uint64
+	flowPC             uint16
+	marks              []uint16
+	symbolsSeq         uint64
+	cycles             bool
 }
 
 type navAction int
@@ -56,20 +65,13 @@ const (
 func NewDisassemblyViewer(rpc *RpcClient, window *Window) *DisassemblyViewer {
 	grid := NewGridWidget(window)
 	grid.SetColumnGap(1)
-	grid.AddColumn("address", 5, ColorAddress.Attr(), nil)
-	grid.AddColumn("opcode1", 2, ColorText.Attr(), nil)
-	grid.AddColumn("opcode2", 2, ColorText.Attr(), nil)
-	grid.AddColumn("opcode3", 2, ColorText.Attr(), nil)
-	grid.AddColumn("mnemonic", 4, ColorMnemonic.Attr(), nil)
-	grid.AddColumn("argument", 14, ColorText.Attr(), disassemblyArgumentAttr)
-	grid.AddColumn("comment", 0, ColorComment.Attr(), nil)
-	grid.SetEditableColumnsRange(4, 6)
 	v := &DisassemblyViewer{
 		BaseWindowComponent: NewBaseWindowComponent(window),
 		rpc:                 rpc,
 		grid:                grid,
 		follow:              true,
 	}
+	v.setCycles(false)
 	grid.SetOnCellInputChange(v.onGridEditChange)
 	v.addressInput = NewAddressInputWidget(window)
 	v.addressInput.SetColor(ColorAddress)
@@ -94,7 +96,13 @@ func (d *DisassemblyViewer) Update(ctx context.Context) (bool, error) {
 	if d.Window().Height() <= 0 {
 		return false, nil
 	}
+	if st.SymbolsSeq != d.symbolsSeq {
+		d.symbolsSeq = st.SymbolsSeq
+		d.lastSnapshot = ""
+	}
 	_ = d.applyPendingWrite(ctx)
+	d.applyPendingAddrExpr(ctx)
+	d.refreshFlow(ctx, st)
 
 	if !d.hasCurrentAddr {
 		if st.DisassemblyAddr != nil {
@@ -148,24 +156,7 @@ func (d *DisassemblyViewer) Update(ctx context.Context) (bool, error) {
 
 	rows := make([]DisasmRow, 0, len(decoded))
 	for _, ins := range decoded {
-		row := DisasmRow{
-			Addr:     ins.Addr,
-			Size:     ins.Size,
-			RawText:  ins.RawText,
-			AsmText:  ins.AsmText,
-			Mnemonic: ins.Mnemonic,
-			Operand:  ins.Operand,
-			Comment:  ins.Comment,
-		}
-		if ins.FlowTarget != nil {
-			v := *ins.FlowTarget
-			row.FlowTarget = &v
-		}
-		if ins.OperandAddrPos != nil {
-			row.HasOperandAddr = true
-			row.OperandAddrPos = *ins.OperandAddrPos
-		}
-		rows = append(rows, row)
+		rows = append(rows, disasmToRow(ins))
 	}
 	if app := d.App(); app != nil {
 		app.DispatchAction(ActionSetDisassemblyRows, rows)
@@ -183,11 +174,16 @@ func (d *DisassemblyViewer) Update(ctx context.Context) (bool, error) {
 
 func (d *DisassemblyViewer) fetchRows(ctx context.Context, addr uint16) ([]disasm.DecodedInstruction, error) {
 	readLen := stLimit(d.Window().Height()*3, 3)
-	data, err := d.rpc.ReadMemoryChunked(ctx, addr, readLen)
+	chunks, err := d.rpc.ReadMemoryV(ctx, []MemoryRange{{Addr: addr, Length: readLen}})
 	if err != nil {
 		return nil, err
 	}
-	decoded := disasm.Decode(addr, data)
+	var decoded []disasm.DecodedInstruction
+	if m := d.flowMap(); m != nil {
+		decoded = m.Decode(addr, chunks[0])
+	} else {
+		decoded = disasm.Decode(addr, chunks[0], State().IllegalOpcodes)
+	}
 	rows := make([]disasm.DecodedInstruction, 0, len(decoded))
 	prev := uint16(0)
 	for i, ins := range decoded {
@@ -204,22 +200,16 @@ func (d *DisassemblyViewer) Render(_force bool) {
 	st := State()
 	w := d.Window()
 	w.SetTagActive("follow", d.follow)
+	w.SetTagActive("illegal", st.IllegalOpcodes)
+	w.SetTagActive("flow", d.flowEnabled)
+	w.SetTagActive("cycles", d.cycles)
 	gridRows := make([][]string, 0, len(st.DisassemblyRows))
 	activeRow := -1
 	for i, row := range st.DisassemblyRows {
 		if activeRow < 0 && row.Addr == st.CPU.PC && !(d.inputMode == inputModeAddr && i == 0) {
 			activeRow = i
 		}
-		op1, op2, op3 := opcodeColumns(row.RawText)
-		gridRows = append(gridRows, []string{
-			formatHex16(row.Addr) + ":",
-			op1,
-			op2,
-			op3,
-			row.Mnemonic,
-			row.Operand,
-			row.Comment,
-		})
+		gridRows = append(gridRows, listingCells(row, d.cycles))
 	}
 	d.grid.SetData(gridRows)
 	visCount := min(len(st.DisassemblyRows), w.Height())
@@ -313,13 +303,12 @@ func (d *DisassemblyViewer) Render(_force bool) {
 	if d.inputMode != inputModeAddr {
 		return
 	}
-	text := strings.ToUpper(d.addressInput.Buffer())
-	if len(text) > 4 {
-		text = text[len(text)-4:]
+	color := ColorAddress
+	if d.addressInput.Invalid() {
+		color = ColorInputInvalid
 	}
-	text = padLeft(text, 4, '0')
 	w.Cursor(0, 0)
-	w.Print(text+"  ", ColorAddress.Attr()|AttrReverse(), false)
+	w.Print(addressInputDisplayText(d.addressInput.Buffer())+"  ", color.Attr()|AttrReverse(), false)
 }
 
 func (d *DisassemblyViewer) HandleInput(ch int) bool {
@@ -336,6 +325,39 @@ func (d *DisassemblyViewer) HandleInput(ch int) bool {
 		d.setFollow(!d.follow)
 		return true
 	}
+	if lower == 'i' {
+		if app := d.App(); app != nil {
+			app.DispatchAction(ActionSetIllegalOpcodes, !st.IllegalOpcodes)
+		}
+		d.flowStale = true
+		d.lastSnapshot = ""
+		return true
+	}
+	if lower == 'g' {
+		d.flowEnabled = !d.flowEnabled
+		d.lastSnapshot = ""
+		return true
+	}
+	if lower == 'y' {
+		d.setCycles(!d.cycles)
+		return true
+	}
+	if lower == 'x' {
+		if d.hasSelectedAddr {
+			if app := d.App(); app != nil {
+				app.DispatchAction(ActionSetXrefsAddr, d.selectedAddr)
+			}
+		}
+		return true
+	}
+	if lower == 'm' {
+		if d.hasSelectedAddr {
+			d.toggleMark(d.selectedAddr)
+			d.flowStale = true
+			d.lastSnapshot = ""
+		}
+		return true
+	}
 	if ch == KeyHome() {
 		d.setFollow(false)
 		d.selectedRowHint = 0
@@ -429,6 +451,7 @@ func (d *DisassemblyViewer) applyPendingWrite(ctx context.Context) error {
 	if err := d.rpc.WriteMemory(ctx, addr, payload); err != nil {
 		return err
 	}
+	d.flowStale = true
 	d.lastSnapshot = ""
 	return nil
 }
@@ -484,6 +507,47 @@ func (d *DisassemblyViewer) applyPendingNav(ctx context.Context) error {
 	return nil
 }
 
+// refreshFlow re-runs code flow analysis when it is missing or invalidated,
+// after every emulator state change while paused, and when the PC leaves
+// known code.
+func (d *DisassemblyViewer) refreshFlow(ctx context.Context, st AppStateData) {
+	if !d.flowEnabled {
+		return
+	}
+	if d.codeFlow != nil && !d.flowStale && !(st.Paused && st.StateSeq != d.flowSeq) &&
+		(st.CPU.PC == d.flowPC || d.codeFlow.Kind(st.CPU.PC) == flow.Code) {
+		return
+	}
+	codeFlow, _, err := d.rpc.AnalyzeFlow(ctx, d.marks, st.IllegalOpcodes)
+	if err != nil {
+		return
+	}
+	d.codeFlow = codeFlow
+	d.flowStale = false
+	d.flowSeq = st.StateSeq
+	d.flowPC = st.CPU.PC
+	d.lastSnapshot = ""
+}
+
+// toggleMark adds addr as a flow analysis entry point, or removes it when it
+// is already marked.
+func (d *DisassemblyViewer) toggleMark(addr uint16) {
+	for i, mark := range d.marks {
+		if mark == addr {
+			d.marks = append(d.marks[:i], d.marks[i+1:]...)
+			return
+		}
+	}
+	d.marks = append(d.marks, addr)
+}
+
+func (d *DisassemblyViewer) flowMap() *flow.Map {
+	if !d.flowEnabled {
+		return nil
+	}
+	return d.codeFlow
+}
+
 func (d *DisassemblyViewer) moveDown(steps int) {
 	rows := State().DisassemblyRows
 	if len(rows) == 0 {
@@ -504,6 +568,9 @@ func (d *DisassemblyViewer) findEndStart(ctx context.Context) (uint16, error) {
 	if targetRow < 0 {
 		targetRow = 0
 	}
+	if m := d.flowMap(); m != nil {
+		return m.Prev(m.RowStart(0xFFFF), targetRow), nil
+	}
 	lookbacks := []int{64, 128, 256, 512, 1024, 2048, 4096, 8192, 16384, 32768, 65535}
 	for _, back := range lookbacks {
 		low := 0xFFFF - back
@@ -511,11 +578,11 @@ func (d *DisassemblyViewer) findEndStart(ctx context.Context) (uint16, error) {
 			low = 0
 		}
 		length := (0xFFFF - low) + 3
-		data, err := d.rpc.ReadMemoryChunked(ctx, uint16(low), length)
+		chunks, err := d.rpc.ReadMemoryV(ctx, []MemoryRange{{Addr: uint16(low), Length: length}})
 		if err != nil {
 			return 0, err
 		}
-		addrs := linearAddrs(disasm.Decode(uint16(low), data))
+		addrs := linearAddrs(disasm.Decode(uint16(low), chunks[0], State().IllegalOpcodes))
 		if len(addrs) == 0 {
 			if low == 0 {
 				return 0, nil
@@ -539,6 +606,9 @@ func (d *DisassemblyViewer) findPrevStartN(ctx context.Context, addr uint16, ste
 	if steps <= 0 {
 		return addr, nil
 	}
+	if m := d.flowMap(); m != nil {
+		return m.Prev(addr, steps), nil
+	}
 	lookbacks := []int{
 		steps*3 + 16,
 		steps*6 + 32,
@@ -558,11 +628,11 @@ func (d *DisassemblyViewer) findPrevStartN(ctx context.Context, addr uint16, ste
 			low = 0
 		}
 		length := int(addr) - low + 3
-		data, err := d.rpc.ReadMemoryChunked(ctx, uint16(low), length)
+		chunks, err := d.rpc.ReadMemoryV(ctx, []MemoryRange{{Addr: uint16(low), Length: length}})
 		if err != nil {
 			return 0, err
 		}
-		addrs := linearAddrs(disasm.Decode(uint16(low), data))
+		addrs := linearAddrs(disasm.Decode(uint16(low), chunks[0], State().IllegalOpcodes))
 		prev := make([]uint16, 0, len(addrs))
 		for _, a := range addrs {
 			if a < addr {
@@ -663,16 +733,32 @@ func (d *DisassemblyViewer) handleEditInput(ch int) bool {
 	return true
 }
 
+// updateAddressInput defers evaluation to Update, since registers and
+// [vector] terms need RPC calls.
 func (d *DisassemblyViewer) updateAddressInput(text string) {
+	d.pendingAddrExpr = text
+}
+
+func (d *DisassemblyViewer) applyPendingAddrExpr(ctx context.Context) {
+	text := d.pendingAddrExpr
 	if text == "" {
 		return
 	}
-	v, err := memory.ParseHex(text)
+	d.pendingAddrExpr = ""
+	v, err := d.rpc.ParseAddress(ctx, text)
+	if d.inputMode == inputModeAddr {
+		d.addressInput.SetInvalid(err != nil)
+	}
 	if err != nil {
 		return
 	}
+	d.GoTo(v)
+}
+
+// GoTo stops following the PC and shows the listing from addr.
+func (d *DisassemblyViewer) GoTo(addr uint16) {
 	d.setFollow(false)
-	d.currentAddr = v
+	d.currentAddr = addr
 	d.hasCurrentAddr = true
 	d.selectedAddr = d.currentAddr
 	d.hasSelectedAddr = true
@@ -717,7 +803,7 @@ func (d *DisassemblyViewer) assembleEditBuffer(text string) []byte {
 	if stmt == "" {
 		return nil
 	}
-	encoded, err := disasm.AssembleOne(d.editAddr, strings.ToUpper(stmt))
+	encoded, err := disasm.AssembleOne(d.editAddr, strings.ToUpper(stmt), State().IllegalOpcodes)
 	if err != nil || len(encoded) == 0 {
 		return nil
 	}
@@ -779,6 +865,16 @@ func (d *DisassemblyViewer) findRowByAddr(addr uint16) (int, bool) {
 	return 0, false
 }
 
+func (d *DisassemblyViewer) setCycles(enabled bool) {
+	d.cycles = enabled
+	setListingColumns(d.grid, 5, disassemblyArgumentAttr, enabled)
+	comment := 6
+	if enabled {
+		comment = 7
+	}
+	d.grid.SetEditableColumnsRange(4, comment)
+}
+
 func (d *DisassemblyViewer) setFollow(enabled bool) {
 	d.follow = enabled
 	d.Window().SetTagActive("follow", enabled)
@@ -908,17 +1004,6 @@ func stLimit(v, minV int) int {
 	return v
 }
 
-func padLeft(text string, n int, fill rune) string {
-	r := []rune(text)
-	for len(r) < n {
-		r = append([]rune{fill}, r...)
-	}
-	if len(r) > n {
-		r = r[len(r)-n:]
-	}
-	return string(r)
-}
-
 func trimLastRune(text string) string {
 	r := []rune(text)
 	if len(r) == 0 {
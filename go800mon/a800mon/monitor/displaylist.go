@@ -6,6 +6,7 @@ import (
 
 	. "go800mon/a800mon"
 	atari "go800mon/a800mon/atari"
+	"go800mon/internal/displaylist"
 )
 
 type DisplayListViewer struct {
@@ -13,20 +14,60 @@ type DisplayListViewer struct {
 	rpc          *RpcClient
 	lastSnapshot string
 	grid         *GridWidget
+	issues       []displaylist.Issue
+	tvpal        *bool
+	scanlines    bool
+	lines        []displaylist.Scanline
+	vscrol       byte
+	bline        int
+	blineMode    byte
+	blineKnown   bool
+	pendingBLine *uint16
 }
 
+// blineOff is the Atari800 power-on BLINE value, outside every scanline.
+const blineOff = 999
+
 func NewDisplayListViewer(rpc *RpcClient, window *Window) *DisplayListViewer {
-	grid := NewGridWidget(window)
-	grid.AddColumn("address", 5, ColorAddress.Attr(), nil)
-	grid.AddColumn("description", 0, ColorText.Attr(), nil)
-	return &DisplayListViewer{
-		BaseWindowComponent: NewBaseWindowComponent(grid.Window()),
+	v := &DisplayListViewer{
+		BaseWindowComponent: NewBaseWindowComponent(window),
 		rpc:                 rpc,
-		grid:                grid,
+		grid:                NewGridWidget(window),
+	}
+	v.setScanlines(false)
+	return v
+}
+
+// setScanlines switches between the compacted entries and one row per
+// scanline.
+func (v *DisplayListViewer) setScanlines(enabled bool) {
+	v.scanlines = enabled
+	v.Window().SetTagActive("scanlines", enabled)
+	g := v.grid
+	g.ClearColumns()
+	g.AddColumn("lint", 1, ColorError.Attr(), nil)
+	if enabled {
+		g.AddColumn("line", 3, ColorAddress.Attr(), nil)
+		g.AddColumn("row", 2, ColorText.Attr(), nil)
+		g.AddColumn("dma", 3, ColorText.Attr(), nil)
+		g.AddColumn("dli", 3, ColorMnemonic.Attr(), nil)
 	}
+	g.AddColumn("address", 5, ColorAddress.Attr(), nil)
+	g.AddColumn("description", 0, ColorText.Attr(), nil)
 }
 
 func (v *DisplayListViewer) Update(ctx context.Context) (bool, error) {
+	// The setting is read once at start and then taken from each set reply.
+	if v.pendingBLine != nil || !v.blineKnown {
+		line := v.pendingBLine
+		v.pendingBLine = nil
+		v.blineKnown = true
+		if scanline, mode, err := v.rpc.BLine(ctx, line); err == nil {
+			v.bline = int(scanline)
+			v.blineMode = mode
+			v.lastSnapshot = ""
+		}
+	}
 	startAddr, err := v.rpc.ReadVector(ctx, atari.DLPTRSAddr)
 	if err != nil {
 		return false, nil
@@ -44,14 +85,27 @@ func (v *DisplayListViewer) Update(ctx context.Context) (bool, error) {
 			dmactl = hw
 		}
 	}
+	antic, err := v.rpc.ANTICState(ctx)
+	if err != nil {
+		return false, nil
+	}
+	if v.tvpal == nil {
+		info, err := v.rpc.Sysinfo(ctx)
+		if err != nil {
+			return false, nil
+		}
+		v.tvpal = &info.TVPAL
+	}
 	dlist := atari.DecodeDisplayList(startAddr, dump)
+	v.issues = dlist.Lint(dmactl, antic.NMIEN, *v.tvpal)
+	v.vscrol = antic.VSCROL
 	if app := v.App(); app != nil {
 		app.DispatchAction(
 			ActionSetDList,
 			DListUpdate{DList: dlist, DMACTL: dmactl},
 		)
 	}
-	snapshot := fmt.Sprintf("%04X|%02X|%d", startAddr, dmactl, len(dlist.Entries))
+	snapshot := fmt.Sprintf("%04X|%02X|%02X|%d|%d", startAddr, dmactl, v.vscrol, len(dlist.Entries), len(v.issues))
 	if len(dlist.Entries) > 0 {
 		first := dlist.Entries[0]
 		last := dlist.Entries[len(dlist.Entries)-1]
@@ -65,17 +119,13 @@ func (v *DisplayListViewer) Update(ctx context.Context) (bool, error) {
 }
 
 func (v *DisplayListViewer) Render(_force bool) {
-	st := State()
-	g := v.grid
-	rows := make([][]string, 0, len(st.DList.Entries))
-	for _, c := range st.DList.Compacted() {
-		addr := fmt.Sprintf("%04X:", c.Entry.Addr)
-		desc := c.Entry.Description()
-		if c.Count > 1 {
-			desc = fmt.Sprintf("%dx %s", c.Count, c.Entry.Description())
-		}
-		rows = append(rows, []string{addr, desc})
+	var rows [][]string
+	if v.scanlines {
+		rows = v.scanlineRows()
+	} else {
+		rows = v.entryRows()
 	}
+	g := v.grid
 	g.SetData(rows)
 	if len(rows) > 0 {
 		if _, ok := g.SelectedRow(); !ok {
@@ -88,6 +138,89 @@ func (v *DisplayListViewer) Render(_force bool) {
 	g.Render()
 }
 
+func (v *DisplayListViewer) entryRows() [][]string {
+	st := State()
+	rows := make([][]string, 0, len(st.DList.Entries)+len(v.issues))
+	compacted := st.DList.Compacted()
+	for i, c := range compacted {
+		addr := fmt.Sprintf("%04X:", c.Entry.Addr)
+		desc := c.Entry.Description()
+		if c.Count > 1 {
+			desc = fmt.Sprintf("%dx %s", c.Count, c.Entry.Description())
+		}
+		// A compacted row covers the entries up to the next row.
+		marker := ""
+		for _, issue := range v.issues {
+			if issue.Addr >= c.Entry.Addr && (i == len(compacted)-1 || issue.Addr < compacted[i+1].Entry.Addr) {
+				marker = "!"
+				break
+			}
+		}
+		rows = append(rows, []string{marker, addr, desc})
+	}
+	for _, issue := range v.issues {
+		rows = append(rows, []string{"!", fmt.Sprintf("%04X:", issue.Addr), issue.Message})
+	}
+	return rows
+}
+
+// scanlineRows lists every scanline; the lint column marks the BLINE
+// breakpoint.
+func (v *DisplayListViewer) scanlineRows() [][]string {
+	st := State()
+	v.lines = st.DList.Scanlines(st.DMACTL, v.vscrol)
+	rows := make([][]string, 0, len(v.lines))
+	for _, sl := range v.lines {
+		marker, dli := "", ""
+		if v.breakLine(sl.Line) {
+			marker = "B"
+		}
+		if sl.DLI {
+			dli = "DLI"
+		}
+		rows = append(rows, []string{
+			marker,
+			fmt.Sprintf("%3d", sl.Line),
+			fmt.Sprintf("%2d", sl.Row),
+			fmt.Sprintf("%3d", sl.DMA),
+			dli,
+			fmt.Sprintf("%04X:", sl.Entry.Addr),
+			sl.Entry.Description(),
+		})
+	}
+	return rows
+}
+
+// breakLine reports whether BLINE breaks or blinks at scanline line.
+func (v *DisplayListViewer) breakLine(line int) bool {
+	switch v.blineMode {
+	case 1:
+		return line == v.bline
+	case 2:
+		return line == v.bline-1000
+	}
+	return false
+}
+
 func (v *DisplayListViewer) HandleInput(ch int) bool {
+	switch ch {
+	case 'v', 'V':
+		v.setScanlines(!v.scanlines)
+		v.grid.SetSelectedRow(nil)
+		v.lastSnapshot = ""
+		return true
+	case 'k', 'K':
+		idx, ok := v.grid.SelectedRow()
+		if !v.scanlines || !ok || idx >= len(v.lines) {
+			return false
+		}
+		// Setting the breakpoint line again clears it.
+		line := uint16(v.lines[idx].Line)
+		if v.breakLine(v.lines[idx].Line) {
+			line = blineOff
+		}
+		v.pendingBLine = &line
+		return true
+	}
 	return v.grid.HandleInput(ch)
 }
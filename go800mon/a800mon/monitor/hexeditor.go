@@ -0,0 +1,374 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	. "go800mon/a800mon"
+	"go800mon/internal/atascii"
+)
+
+// HexEditor is a scrollable hex and text view of memory. '/' goes to an
+// address, Enter starts nibble editing, 'a' switches the text column
+// between ATASCII and screen codes. Bytes that changed with the last
+// emulator state change are highlighted.
+type HexEditor struct {
+	BaseWindowComponent
+	rpc          *RpcClient
+	grid         *GridWidget
+	addressInput *AddressInputWidget
+	inputActive  bool
+	editing      bool
+	screenCodes  bool
+	lineBytes    int
+	top          uint16
+	cursor       uint16
+	lowNibble    bool
+	mem          [0x10000]byte
+	known        [0x10000]bool
+	base         [0x10000]byte
+	baseKnown    [0x10000]bool
+	changed      map[uint16]bool
+	seq          uint64
+	pendingAddr  string
+	writes       []hexWrite
+	message      string
+	lastSnapshot string
+	nextRPCAt    time.Time
+}
+
+type hexWrite struct {
+	addr  uint16
+	value byte
+}
+
+func NewHexEditor(rpc *RpcClient, window *Window) *HexEditor {
+	v := &HexEditor{
+		BaseWindowComponent: NewBaseWindowComponent(window),
+		rpc:                 rpc,
+		grid:                NewGridWidget(window),
+		addressInput:        NewAddressInputWidget(window),
+		changed:             map[uint16]bool{},
+	}
+	v.grid.SetSelectionEnabled(false)
+	v.addressInput.SetColor(ColorAddress)
+	v.setLineBytes(8)
+	window.SetVisible(false)
+	return v
+}
+
+// setLineBytes rebuilds the columns for n bytes per line.
+func (v *HexEditor) setLineBytes(n int) {
+	v.lineBytes = n
+	v.top &^= uint16(n - 1)
+	v.grid.ClearColumns()
+	v.grid.AddColumn("address", 5, ColorAddress.Attr(), nil)
+	for i := 0; i < n; i++ {
+		v.grid.AddColumn(fmt.Sprintf("byte%d", i), 2, ColorText.Attr(), v.byteAttr(i))
+	}
+	v.grid.AddColumn("text", 0, ColorComment.Attr(), nil)
+	v.lastSnapshot = ""
+}
+
+// byteAttr marks the cursor and the bytes in changed for byte column i.
+func (v *HexEditor) byteAttr(i int) GridAttrCallback {
+	return func(value string, row []string) int {
+		line, err := parseHexRowAddr(row[0])
+		if err != nil {
+			return ColorText.Attr()
+		}
+		addr := line + uint16(i)
+		attr := ColorText.Attr()
+		if v.changed[addr] {
+			attr = ColorError.Attr()
+		}
+		if addr == v.cursor {
+			attr |= AttrReverse()
+		}
+		return attr
+	}
+}
+
+func parseHexRowAddr(text string) (uint16, error) {
+	var addr uint16
+	_, err := fmt.Sscanf(text, "%04X:", &addr)
+	return addr, err
+}
+
+func (v *HexEditor) pageRows() int {
+	rows := v.Window().Height()
+	if v.inputActive {
+		rows--
+	}
+	return max(1, rows)
+}
+
+func (v *HexEditor) Update(ctx context.Context) (bool, error) {
+	if !v.Window().Visible() || v.inputActive {
+		return false, nil
+	}
+	lineBytes := 8
+	if v.Window().Width() >= 5+16*3+16 {
+		lineBytes = 16
+	}
+	if lineBytes != v.lineBytes {
+		v.setLineBytes(lineBytes)
+	}
+	forced := v.applyPending(ctx)
+	now := time.Now()
+	if !forced && now.Before(v.nextRPCAt) {
+		return false, nil
+	}
+	v.nextRPCAt = now.Add(200 * time.Millisecond)
+	st := State()
+	length := v.pageRows() * v.lineBytes
+	data, err := v.rpc.ReadMemory(ctx, v.top, uint16(min(length, 0x10000-int(v.top))))
+	if err != nil {
+		return false, nil
+	}
+	// base holds the bytes as they were at seq; bytes first seen since then
+	// join it so they are compared at the next state change.
+	seqChanged := st.StateSeq != v.seq
+	if seqChanged {
+		v.seq = st.StateSeq
+		v.changed = map[uint16]bool{}
+	}
+	for i, b := range data {
+		addr := v.top + uint16(i)
+		if seqChanged && v.baseKnown[addr] && v.base[addr] != b {
+			v.changed[addr] = true
+		}
+		if seqChanged || !v.baseKnown[addr] {
+			v.base[addr], v.baseKnown[addr] = b, true
+		}
+		v.mem[addr], v.known[addr] = b, true
+	}
+	title := fmt.Sprintf("Hex Editor %04X", v.cursor)
+	if v.message != "" {
+		title += " " + v.message
+	}
+	rows := v.buildRows(len(data))
+	snapshot := fmt.Sprintf("%s|%v|%v|%t|%t", title, rows, v.changed, v.editing, v.lowNibble)
+	if snapshot == v.lastSnapshot {
+		return false, nil
+	}
+	v.lastSnapshot = snapshot
+	v.Window().SetTitle(title)
+	v.grid.SetData(rows)
+	return true, nil
+}
+
+// applyPending resolves a go-to address and sends queued writes. It reports
+// whether anything was done, so the view is refreshed right away.
+func (v *HexEditor) applyPending(ctx context.Context) bool {
+	done := false
+	if v.pendingAddr != "" {
+		addr, err := v.rpc.ParseAddress(ctx, v.pendingAddr)
+		v.pendingAddr = ""
+		if err != nil {
+			v.message = err.Error()
+		} else {
+			v.message = ""
+			v.moveTo(addr)
+			v.top = v.cursor &^ uint16(v.lineBytes-1)
+		}
+		done = true
+	}
+	for _, w := range v.writes {
+		if err := v.rpc.WriteMemory(ctx, w.addr, []byte{w.value}); err != nil {
+			v.message = err.Error()
+		}
+		done = true
+	}
+	v.writes = nil
+	return done
+}
+
+func (v *HexEditor) buildRows(n int) [][]string {
+	var rows [][]string
+	for off := 0; off < n; off += v.lineBytes {
+		line := v.top + uint16(off)
+		row := []string{fmt.Sprintf("%04X:", line)}
+		var text strings.Builder
+		for i := 0; i < v.lineBytes; i++ {
+			if off+i >= n {
+				row = append(row, "")
+				continue
+			}
+			b := v.mem[line+uint16(i)]
+			row = append(row, fmt.Sprintf("%02X", b))
+			text.WriteRune(v.textChar(b))
+		}
+		rows = append(rows, append(row, text.String()))
+	}
+	return rows
+}
+
+func (v *HexEditor) textChar(b byte) rune {
+	if v.screenCodes {
+		ch, _ := renderScreenCharATASCII(b)
+		return ch
+	}
+	r := []rune(atascii.LookupPrintable(b & 0x7F))
+	if len(r) == 0 {
+		return '.'
+	}
+	return r[0]
+}
+
+func (v *HexEditor) Render(_force bool) {
+	w := v.Window()
+	w.SetTagActive("edit", v.editing)
+	w.SetTagActive("atascii", !v.screenCodes)
+	w.SetTagActive("screen", v.screenCodes)
+	overlayRows := 0
+	if v.inputActive {
+		overlayRows = 1
+	}
+	v.grid.SetViewport(overlayRows, max(0, w.Height()-overlayRows))
+	v.grid.Render()
+	if v.inputActive {
+		v.addressInput.Render(false)
+	}
+}
+
+// moveTo places the cursor on addr and scrolls it into view.
+func (v *HexEditor) moveTo(addr uint16) {
+	v.cursor = addr
+	v.lowNibble = false
+	page := v.pageRows() * v.lineBytes
+	line := addr &^ uint16(v.lineBytes-1)
+	switch {
+	case line < v.top:
+		v.top = line
+	case int(line) >= int(v.top)+page:
+		v.top = line - uint16(page-v.lineBytes)
+	}
+	v.lastSnapshot = ""
+	v.nextRPCAt = time.Time{}
+}
+
+func (v *HexEditor) moveBy(delta int) {
+	addr := int(v.cursor) + delta
+	v.moveTo(uint16(max(0, min(0xFFFF, addr))))
+}
+
+func (v *HexEditor) handleNavigation(ch int) bool {
+	page := v.pageRows() * v.lineBytes
+	switch ch {
+	case KeyLeft():
+		v.moveBy(-1)
+	case KeyRight():
+		v.moveBy(1)
+	case KeyUp():
+		v.moveBy(-v.lineBytes)
+	case KeyDown():
+		v.moveBy(v.lineBytes)
+	case KeyPageUp(), 339:
+		v.moveBy(-page)
+	case KeyPageDown(), 338:
+		v.moveBy(page)
+	case KeyHome(), 262:
+		v.moveTo(0)
+	case KeyEnd(), 360:
+		v.moveTo(0xFFFF)
+	default:
+		return false
+	}
+	return true
+}
+
+func (v *HexEditor) HandleInput(ch int) bool {
+	if v.handleNavigation(ch) {
+		return true
+	}
+	switch ch {
+	case 'a', 'A':
+		v.screenCodes = !v.screenCodes
+		v.lastSnapshot = ""
+		v.nextRPCAt = time.Time{}
+		return true
+	case '/':
+		v.inputActive = true
+		v.addressInput.Activate(formatHex16(v.cursor))
+		v.setInputFocus(v.handleAddressInput)
+		return true
+	case 10, 13, KeyEnter():
+		v.editing = true
+		v.lowNibble = false
+		v.lastSnapshot = ""
+		v.setInputFocus(v.handleEditInput)
+		return true
+	}
+	return false
+}
+
+func (v *HexEditor) setInputFocus(handler func(int) bool) {
+	if app := v.App(); app != nil {
+		if handler == nil {
+			app.DispatchAction(ActionSetInputFocus, nil)
+		} else {
+			app.DispatchAction(ActionSetInputFocus, handler)
+		}
+	}
+}
+
+func (v *HexEditor) handleAddressInput(ch int) bool {
+	if ch == 27 || ch == 10 || ch == 13 || ch == KeyEnter() {
+		text := strings.TrimSpace(v.addressInput.Buffer())
+		if ch != 27 && text != "" {
+			v.pendingAddr = text
+		}
+		v.inputActive = false
+		v.addressInput.Deactivate()
+		v.lastSnapshot = ""
+		v.nextRPCAt = time.Time{}
+		v.setInputFocus(nil)
+		return true
+	}
+	v.addressInput.HandleKey(ch)
+	return true
+}
+
+// handleEditInput overwrites the nibble under the cursor with each hex
+// digit typed, writing the byte at once, and moves on after the low nibble.
+func (v *HexEditor) handleEditInput(ch int) bool {
+	if ch == 27 || ch == 10 || ch == 13 || ch == KeyEnter() {
+		v.editing = false
+		v.lowNibble = false
+		v.lastSnapshot = ""
+		v.setInputFocus(nil)
+		return true
+	}
+	if v.handleNavigation(ch) {
+		return true
+	}
+	digit := strings.IndexRune("0123456789ABCDEF", toUpperRune(ch))
+	if digit < 0 || !v.known[v.cursor] {
+		return true
+	}
+	old := v.mem[v.cursor]
+	value := old&0x0F | byte(digit)<<4
+	if v.lowNibble {
+		value = old&0xF0 | byte(digit)
+	}
+	v.mem[v.cursor] = value
+	v.writes = append(v.writes, hexWrite{addr: v.cursor, value: value})
+	if v.lowNibble {
+		v.moveBy(1)
+	} else {
+		v.lowNibble = true
+	}
+	v.lastSnapshot = ""
+	v.nextRPCAt = time.Time{}
+	return true
+}
+
+func toUpperRune(ch int) rune {
+	if ch >= 'a' && ch <= 'z' {
+		return rune(ch - 32)
+	}
+	return rune(ch)
+}
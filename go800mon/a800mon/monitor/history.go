@@ -3,6 +3,8 @@ package monitor
 import (
 	"context"
 	"fmt"
+	"slices"
+	"strconv"
 	"strings"
 
 	. "go800mon/a800mon"
@@ -21,26 +23,22 @@ type HistoryViewer struct {
 	reverseOrder bool
 	lastSnapshot string
 	nextRow      *DisasmRow
-	decodeCache  map[string]DisasmRow
+	decodeCache  map[string]disasm.DecodedInstruction
 	followLive   bool
+	illegal      bool
+	cycles       bool
 }
 
 func NewHistoryViewer(rpc *RpcClient, window *Window, reverseOrder bool) *HistoryViewer {
 	grid := NewGridWidget(window)
 	grid.SetColumnGap(1)
-	grid.AddColumn("address", 5, ColorAddress.Attr(), nil)
-	grid.AddColumn("opcode1", 2, ColorText.Attr(), nil)
-	grid.AddColumn("opcode2", 2, ColorText.Attr(), nil)
-	grid.AddColumn("opcode3", 2, ColorText.Attr(), nil)
-	grid.AddColumn("mnemonic", 4, ColorMnemonic.Attr(), nil)
-	grid.AddColumn("argument", 14, ColorText.Attr(), historyArgumentAttr)
-	grid.AddColumn("comment", 0, ColorComment.Attr(), nil)
+	setListingColumns(grid, 4, historyArgumentAttr, false)
 	return &HistoryViewer{
 		BaseWindowComponent: NewBaseWindowComponent(grid.Window()),
 		rpc:                 rpc,
 		grid:                grid,
 		reverseOrder:        reverseOrder,
-		decodeCache:         map[string]DisasmRow{},
+		decodeCache:         map[string]disasm.DecodedInstruction{},
 		followLive:          true,
 	}
 }
@@ -54,8 +52,13 @@ func (h *HistoryViewer) Update(ctx context.Context) (bool, error) {
 		app.DispatchAction(ActionSetHistory, entries)
 	}
 	st := State()
+	if h.illegal != st.IllegalOpcodes {
+		h.illegal = st.IllegalOpcodes
+		h.decodeCache = map[string]disasm.DecodedInstruction{}
+		h.lastSnapshot = ""
+	}
 	if code, err := h.rpc.ReadMemory(ctx, st.CPU.PC, 3); err == nil {
-		if ins := disasm.DecodeOne(st.CPU.PC, code); ins != nil {
+		if ins := disasm.DecodeOne(st.CPU.PC, code, h.illegal); ins != nil {
 			row := disasmToRow(*ins)
 			h.nextRow = &row
 		}
@@ -87,20 +90,20 @@ func (h *HistoryViewer) Render(_force bool) {
 		row := DisasmRow{Addr: st.CPU.PC, RawText: "", Comment: st.CPUDisasm}
 		next = &row
 	}
+	h.Window().SetTagActive("cycles", h.cycles)
 	rows := make([][]string, 0, len(st.History)+1)
+	rows = append(rows, listingCells(*next, h.cycles))
+	for i, e := range st.History {
+		nextPC := st.CPU.PC
+		if i > 0 {
+			nextPC = st.History[i-1].PC
+		}
+		rows = append(rows, h.historyRowCells(e, nextPC))
+	}
 	selected := 0
 	if h.reverseOrder {
-		for _, e := range reverseHistory(st.History) {
-			rows = append(rows, h.historyRowCells(e))
-		}
-		rows = append(rows, h.decodedRowCells(*next))
+		slices.Reverse(rows)
 		selected = len(rows) - 1
-	} else {
-		rows = append(rows, h.decodedRowCells(*next))
-		for _, e := range st.History {
-			rows = append(rows, h.historyRowCells(e))
-		}
-		selected = 0
 	}
 	h.grid.SetData(rows)
 	if len(rows) == 0 {
@@ -114,6 +117,11 @@ func (h *HistoryViewer) Render(_force bool) {
 }
 
 func (h *HistoryViewer) HandleInput(ch int) bool {
+	if ch == 'y' || ch == 'Y' {
+		h.cycles = !h.cycles
+		setListingColumns(h.grid, 4, historyArgumentAttr, h.cycles)
+		return true
+	}
 	if !h.grid.HandleInput(ch) {
 		return false
 	}
@@ -125,48 +133,76 @@ func (h *HistoryViewer) HandleInput(ch int) bool {
 	return true
 }
 
-func (h *HistoryViewer) decodeHistoryRow(entry CpuHistoryEntry) DisasmRow {
+func (h *HistoryViewer) decodeHistoryEntry(entry CpuHistoryEntry) disasm.DecodedInstruction {
 	key := fmt.Sprintf("%04X-%02X-%02X-%02X", entry.PC, entry.Op0, entry.Op1, entry.Op2)
-	row, ok := h.decodeCache[key]
+	ins, ok := h.decodeCache[key]
 	if !ok {
-		if ins := disasm.DecodeOne(entry.PC, entry.OpBytes()); ins != nil {
-			row = disasmToRow(*ins)
+		if decoded := disasm.DecodeOne(entry.PC, entry.OpBytes(), h.illegal); decoded != nil {
+			ins = *decoded
 		} else {
-			row = DisasmRow{Addr: entry.PC}
+			ins = disasm.DecodedInstruction{Addr: entry.PC}
 		}
-		h.decodeCache[key] = row
+		h.decodeCache[key] = ins
 		if len(h.decodeCache) > 4096 {
-			h.decodeCache = map[string]DisasmRow{}
+			h.decodeCache = map[string]disasm.DecodedInstruction{}
 		}
 	}
-	return row
+	return ins
 }
 
-func (h *HistoryViewer) historyRowCells(entry CpuHistoryEntry) []string {
-	return h.decodedRowCells(h.decodeHistoryRow(entry))
+// historyRowCells lists an executed instruction. Its cycles are resolved from
+// the recorded index registers and nextPC, the instruction that ran after it.
+func (h *HistoryViewer) historyRowCells(entry CpuHistoryEntry, nextPC uint16) []string {
+	ins := h.decodeHistoryEntry(entry)
+	row := disasmToRow(ins)
+	if cycles, ok := ins.ExecCycles(entry.X, entry.Y, nextPC); ok && ins.Cycles > 0 {
+		row.Cycles = strconv.Itoa(cycles)
+	}
+	return listingCells(row, h.cycles)
 }
 
-func (h *HistoryViewer) decodedRowCells(row DisasmRow) []string {
+// setListingColumns lays out the instruction columns shared by the
+// Disassembler and History windows.
+func setListingColumns(grid *GridWidget, mnemonicWidth int, argumentAttr GridAttrCallback, cycles bool) {
+	grid.ClearColumns()
+	grid.AddColumn("address", 5, ColorAddress.Attr(), nil)
+	grid.AddColumn("opcode1", 2, ColorText.Attr(), nil)
+	grid.AddColumn("opcode2", 2, ColorText.Attr(), nil)
+	grid.AddColumn("opcode3", 2, ColorText.Attr(), nil)
+	grid.AddColumn("mnemonic", mnemonicWidth, ColorMnemonic.Attr(), nil)
+	grid.AddColumn("argument", 14, ColorText.Attr(), argumentAttr)
+	if cycles {
+		grid.AddColumn("cycles", 4, ColorText.Attr(), nil)
+	}
+	grid.AddColumn("comment", 0, ColorComment.Attr(), nil)
+}
+
+func listingCells(row DisasmRow, cycles bool) []string {
 	op1, op2, op3 := opcodeColumns(row.RawText)
-	return []string{
+	cells := []string{
 		formatHex16(row.Addr) + ":",
 		op1,
 		op2,
 		op3,
 		row.Mnemonic,
 		row.Operand,
-		row.Comment,
 	}
+	if cycles {
+		cells = append(cells, row.Cycles)
+	}
+	return append(cells, row.Comment)
 }
 
 func disasmToRow(ins disasm.DecodedInstruction) DisasmRow {
 	row := DisasmRow{
 		Addr:     ins.Addr,
+		Size:     ins.Size,
 		RawText:  ins.RawText,
 		AsmText:  ins.AsmText,
 		Mnemonic: ins.Mnemonic,
 		Operand:  ins.Operand,
 		Comment:  ins.Comment,
+		Cycles:   ins.CyclesText(),
 	}
 	if ins.FlowTarget != nil {
 		v := *ins.FlowTarget
@@ -179,15 +215,6 @@ func disasmToRow(ins disasm.DecodedInstruction) DisasmRow {
 	return row
 }
 
-func reverseHistory(entries []CpuHistoryEntry) []CpuHistoryEntry {
-	n := len(entries)
-	out := make([]CpuHistoryEntry, 0, n)
-	for i := n - 1; i >= 0; i-- {
-		out = append(out, entries[i])
-	}
-	return out
-}
-
 func opcodeColumns(rawText string) (string, string, string) {
 	parts := strings.Fields(rawText)
 	op1 := ""
@@ -172,7 +172,7 @@ type AddressInputWidget struct {
 
 func NewAddressInputWidget(window *Window) *AddressInputWidget {
 	w := NewInputWidget(window)
-	w.SetMaxLength(4)
+	w.SetMaxLength(24)
 	w.SetCharNormalizer(func(r rune) rune {
 		if r >= 'a' && r <= 'z' {
 			return r - 32
@@ -180,7 +180,7 @@ func NewAddressInputWidget(window *Window) *AddressInputWidget {
 		return r
 	})
 	w.SetCharValidator(func(r rune) bool {
-		return (r >= '0' && r <= '9') || (r >= 'A' && r <= 'F')
+		return (r >= '0' && r <= '9') || (r >= 'A' && r <= 'Z') || strings.ContainsRune("$#+-[]_.@?", r)
 	})
 	return &AddressInputWidget{InputWidget: w}
 }
@@ -196,7 +196,7 @@ func (a *AddressInputWidget) Render(_force bool) {
 	text := addressInputDisplayText(a.buffer)
 	w.Print(text, attr, false)
 	w.FillToEOL(' ', attr)
-	w.Cursor(4, 0)
+	w.Cursor(len(text), 0)
 }
 
 func normalizeRuneIdentity(r rune) rune {
@@ -207,14 +207,12 @@ func validatePrintableASCII(r rune) bool {
 	return r >= 32 && r <= 126
 }
 
+// addressInputDisplayText zero-pads short hex input; expressions are shown
+// as typed.
 func addressInputDisplayText(text string) string {
 	upper := strings.ToUpper(text)
-	runes := []rune(upper)
-	if len(runes) > 4 {
-		runes = runes[len(runes)-4:]
+	if len(upper) > 4 || strings.Trim(upper, "0123456789ABCDEF") != "" {
+		return upper
 	}
-	for len(runes) < 4 {
-		runes = append([]rune{'0'}, runes...)
-	}
-	return string(runes)
+	return strings.Repeat("0", 4-len(upper)) + upper
 }
@@ -94,18 +94,31 @@ func MonitorRun(ctx context.Context, socketPath string) error {
 
 	wcpu := NewWindow("CPU State", true)
 	wdlist := NewWindow("DisplayList", true)
+	wdlist.AddTag("SCANLINES", "scanlines", false)
 	wwatch := NewWindow("Watchers", true)
 	wscreen := NewWindow("Screen Buffer", true)
 	wscreen.AddTag("ATASCII", "atascii", true)
 	wscreen.AddTag("ASCII", "ascii", false)
+	wscreen.AddTag("CHARSET", "charset", false)
+	wpmg := NewWindow("P/M Graphics", true)
+	wdiff := NewWindow("Memory Diff", true)
+	whex := NewWindow("Hex Editor", true)
+	whex.AddTag("EDIT", "edit", false)
+	whex.AddTag("ATASCII", "atascii", true)
+	whex.AddTag("SCREEN", "screen", false)
 	wdisasm := NewWindow("Disassembler", true)
 	wdisasm.AddTag("FOLLOW", "follow", true)
+	wdisasm.AddTag("ILLEGAL", "illegal", false)
+	wdisasm.AddTag("FLOW", "flow", false)
+	wdisasm.AddTag("CYCLES", "cycles", false)
 	whistory := NewWindow("History", true)
+	whistory.AddTag("CYCLES", "cycles", false)
 	wbreakpoints := NewWindow("Breakpoints", true)
 	wbreakpoints.AddTag("ENABLED", "bp_enabled", false)
+	wxrefs := NewWindow("Xrefs", true)
 	top := NewWindow("", false)
 	bottom := NewWindow("", false)
-	screen.SetFocusOrder(wdlist, wwatch, wscreen, wdisasm, whistory, wbreakpoints)
+	screen.SetFocusOrder(wdlist, wwatch, wscreen, wpmg, wdiff, whex, wdisasm, whistory, wbreakpoints)
 
 	statusUpdater := NewStatusUpdater(rpc, dispatcher, 200*time.Millisecond, 50*time.Millisecond)
 
@@ -114,6 +127,10 @@ func MonitorRun(ctx context.Context, socketPath string) error {
 	watchersView := NewWatchersViewer(rpc, wwatch)
 	breakpointsView := NewBreakpointsViewer(rpc, wbreakpoints)
 	historyView := NewHistoryViewer(rpc, whistory, true)
+	xrefsView := NewXrefsViewer(rpc, wxrefs, screen)
+	pmgView := NewPMGViewer(rpc, wpmg)
+	diffView := NewMemoryDiffViewer(rpc, wdiff)
+	hexView := NewHexEditor(rpc, whex)
 	displayList := NewDisplayListViewer(rpc, wdlist)
 	cpu := NewCpuStateViewer(wcpu)
 	topbar := NewTopBar(top)
@@ -125,6 +142,25 @@ func MonitorRun(ctx context.Context, socketPath string) error {
 	layout := func(scr *Screen) {
 		w, h := scr.Size()
 		topY := 1
+		// The optional P/M, diff and hex editor windows stack below the
+		// screen buffer.
+		placeScreen := func(x, width, height int) {
+			column := []*Window{wscreen}
+			for _, win := range []*Window{wpmg, wdiff, whex} {
+				if win.Visible() {
+					column = append(column, win)
+				}
+			}
+			y := topY
+			for i, win := range column {
+				winH := max(1, height/len(column))
+				if i == len(column)-1 {
+					winH = max(1, topY+height-y)
+				}
+				win.Reshape(x, y, width, winH)
+				y += winH
+			}
+		}
 		breakpointsHTarget := 13
 		wcpu.Reshape(0, h-4, w, 3)
 		oldUpperH := wcpu.Y() - topY - 1
@@ -181,7 +217,7 @@ func MonitorRun(ctx context.Context, socketPath string) error {
 			if screenW < 1 {
 				screenW = 1
 			}
-			wscreen.Reshape(rightX, topY, screenW, upperH)
+			placeScreen(rightX, screenW, upperH)
 			disasmX := rightX + screenW + gap
 			wdisasm.Reshape(disasmX, topY, disasmW, upperH)
 			historyX := disasmX + disasmW + gap
@@ -200,7 +236,7 @@ func MonitorRun(ctx context.Context, socketPath string) error {
 			}
 		} else {
 			screenW := baseScreenW
-			wscreen.Reshape(rightX, topY, screenW, upperH)
+			placeScreen(rightX, screenW, upperH)
 			historyX := rightX + screenW + gap
 			historyH := upperH
 			if historyH < 1 {
@@ -218,6 +254,7 @@ func MonitorRun(ctx context.Context, socketPath string) error {
 		}
 		top.Reshape(0, 0, w, 1)
 		bottom.Reshape(0, h-1, w, 1)
+		xrefsView.fitToHost()
 	}
 	screen.SetLayoutInitializer(layout)
 	dispatcher.SetInputFocusHandler(screen.SetInputFocus)
@@ -237,13 +274,17 @@ func MonitorRun(ctx context.Context, socketPath string) error {
 	app.AddComponent(displayList)
 	app.AddComponent(screenInspector)
 	app.AddComponent(historyView)
+	app.AddComponent(pmgView)
+	app.AddComponent(diffView)
+	app.AddComponent(hexView)
+	app.AddComponent(xrefsView)
 
-	buildShortcuts(shortcuts, dispatcher, screen, wdlist, whistory, wscreen, wwatch, wbreakpoints, wdisasm, app, disassemblyView)
+	buildShortcuts(shortcuts, dispatcher, screen, wdlist, whistory, wscreen, wwatch, wbreakpoints, wdisasm, wpmg, wdiff, whex, app, disassemblyView, xrefsView)
 
 	return app.Loop(ctx)
 }
 
-func buildShortcuts(shortcuts *ShortcutManager, dispatcher *ActionDispatcher, screen *Screen, wdlist, whistory, wscreen, wwatch, wbreakpoints, wdisasm *Window, app *App, disassemblyView *DisassemblyViewer) {
+func buildShortcuts(shortcuts *ShortcutManager, dispatcher *ActionDispatcher, screen *Screen, wdlist, whistory, wscreen, wwatch, wbreakpoints, wdisasm, wpmg, wdiff, whex *Window, app *App, disassemblyView *DisassemblyViewer, xrefsView *XrefsViewer) {
 	action := func(key int, label string, a Action) Shortcut {
 		return NewShortcut(key, label, func() { _ = dispatcher.Dispatch(a, nil) })
 	}
@@ -298,6 +339,11 @@ func buildShortcuts(shortcuts *ShortcutManager, dispatcher *ActionDispatcher, sc
 		}
 		screen.Focus(wdisasm)
 	}
+	xrefsView.disasm = disassemblyView
+	xrefsView.onJump = func(addr uint16) {
+		toggleDisasm()
+		disassemblyView.GoTo(addr)
+	}
 
 	wdlist.AddHotkey('l', "DisplayList", func() { screen.Focus(wdlist) }, false)
 	whistory.AddHotkey('h', "History", func() { screen.Focus(whistory) }, false)
@@ -310,6 +356,27 @@ func buildShortcuts(shortcuts *ShortcutManager, dispatcher *ActionDispatcher, sc
 		false,
 	)
 	wdisasm.AddHotkey('d', "Disassembly", toggleDisasm, false)
+	// Optional windows open and focus on the first press and close once
+	// focused.
+	toggleOptional := func(w *Window) func() {
+		return func() {
+			switch {
+			case !w.Visible():
+				w.SetVisible(true)
+				app.RebuildScreen()
+				screen.Focus(w)
+			case screen.Focused() != w:
+				screen.Focus(w)
+			default:
+				w.SetVisible(false)
+				screen.Focus(wscreen)
+				app.RebuildScreen()
+			}
+		}
+	}
+	wpmg.AddHotkey('p', "P/M Graphics", toggleOptional(wpmg), false)
+	wdiff.AddHotkey('u', "Memory Diff", toggleOptional(wdiff), false)
+	whex.AddHotkey('r', "Hex Editor", toggleOptional(whex), false)
 	nextWindow := NewShortcut(9, "Next window", screen.FocusNext)
 	nextWindow.VisibleInGlobalBar = false
 	_ = shortcuts.AddGlobal(nextWindow)
@@ -317,5 +384,6 @@ func buildShortcuts(shortcuts *ShortcutManager, dispatcher *ActionDispatcher, sc
 	prevWindow.VisibleInGlobalBar = false
 	_ = shortcuts.AddGlobal(prevWindow)
 	_ = shortcuts.AddGlobal(action(KeyF(9), "Freeze", ActionToggleFreeze))
+	_ = shortcuts.AddGlobal(action(18, "Reload symbols", ActionReloadSymbols))
 	_ = shortcuts.AddGlobal(action('q', "Quit", ActionQuit))
 }
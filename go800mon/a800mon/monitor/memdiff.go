@@ -0,0 +1,189 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	. "go800mon/a800mon"
+)
+
+const memDiffLineBytes = 8
+
+// MemoryDiffViewer compares live memory with a baseline snapshot, captured
+// with 'n' or loaded from disk with 'o', and lists the lines that changed
+// with the changed bytes highlighted.
+type MemoryDiffViewer struct {
+	BaseWindowComponent
+	rpc            *RpcClient
+	grid           *GridWidget
+	inputWidget    *InputWidget
+	inputActive    bool
+	base           *MemorySnapshot
+	pendingCapture bool
+	message        string
+	lastTitle      string
+	lastRows       [][]string
+	liveBase       *MemorySnapshot
+	liveSeq        uint64
+	nextRPCAt      time.Time
+}
+
+func NewMemoryDiffViewer(rpc *RpcClient, window *Window) *MemoryDiffViewer {
+	grid := NewGridWidget(window)
+	grid.AddColumn("address", 5, ColorAddress.Attr(), nil)
+	for i := 0; i < memDiffLineBytes; i++ {
+		grid.AddColumn(fmt.Sprintf("byte%d", i), 2, ColorText.Attr(), memDiffByteAttr(i))
+	}
+	grid.AddColumn("was", 0, ColorComment.Attr(), nil)
+	window.SetVisible(false)
+	return &MemoryDiffViewer{
+		BaseWindowComponent: NewBaseWindowComponent(window),
+		rpc:                 rpc,
+		grid:                grid,
+		inputWidget:         NewInputWidget(window),
+		message:             "Press n to capture a baseline or o to load a snapshot.",
+	}
+}
+
+// memDiffByteAttr highlights byte column i when it differs from the same
+// byte in the "was" column.
+func memDiffByteAttr(i int) GridAttrCallback {
+	return func(value string, row []string) int {
+		was := strings.Fields(row[len(row)-1])
+		if i < len(was) && was[i] != value {
+			return ColorError.Attr()
+		}
+		return ColorText.Attr()
+	}
+}
+
+func (v *MemoryDiffViewer) Update(ctx context.Context) (bool, error) {
+	now := time.Now()
+	if !v.Window().Visible() || v.inputActive {
+		return false, nil
+	}
+	if v.pendingCapture {
+		v.pendingCapture = false
+		snap, err := v.rpc.CaptureSnapshot(ctx, "baseline", []MemoryRange{{Addr: 0, Length: 0x10000}})
+		if err != nil {
+			v.message = err.Error()
+		} else {
+			v.base = &snap
+		}
+		v.nextRPCAt = time.Time{}
+	}
+	if now.Before(v.nextRPCAt) {
+		return false, nil
+	}
+	v.nextRPCAt = now.Add(500 * time.Millisecond)
+	var rows [][]string
+	title := "Memory Diff"
+	if v.base == nil {
+		row := make([]string, memDiffLineBytes+2)
+		row[len(row)-1] = v.message
+		rows = append(rows, row)
+	} else {
+		st := State()
+		if st.Paused && v.liveBase == v.base && v.liveSeq == st.StateSeq {
+			return false, nil
+		}
+		live, err := v.rpc.CaptureSnapshot(ctx, "live", v.base.MemoryRanges())
+		if err != nil {
+			return false, nil
+		}
+		changes := v.base.Diff(live)
+		title = fmt.Sprintf("Memory Diff %s seq=%d: %d bytes", v.base.Name, v.base.StateSeq, len(changes))
+		rows = diffRows(live, changes)
+		v.liveBase, v.liveSeq = v.base, st.StateSeq
+	}
+	if title == v.lastTitle && slices.EqualFunc(rows, v.lastRows, slices.Equal[[]string]) {
+		return false, nil
+	}
+	v.lastTitle, v.lastRows = title, rows
+	v.Window().SetTitle(title)
+	v.grid.SetData(rows)
+	return true, nil
+}
+
+// diffRows lists each line holding a change with its live bytes followed by
+// the baseline ones.
+func diffRows(live MemorySnapshot, changes []ByteChange) [][]string {
+	var cur [0x10000]byte
+	for _, rg := range live.Ranges {
+		copy(cur[rg.Addr:], rg.Data)
+	}
+	var rows [][]string
+	for i := 0; i < len(changes); {
+		line := int(changes[i].Addr) &^ (memDiffLineBytes - 1)
+		was := append([]byte(nil), cur[line:line+memDiffLineBytes]...)
+		for ; i < len(changes) && int(changes[i].Addr)&^(memDiffLineBytes-1) == line; i++ {
+			was[int(changes[i].Addr)-line] = changes[i].Old
+		}
+		row := []string{fmt.Sprintf("%04X:", line)}
+		wasText := make([]string, memDiffLineBytes)
+		for j := range was {
+			row = append(row, fmt.Sprintf("%02X", cur[line+j]))
+			wasText[j] = fmt.Sprintf("%02X", was[j])
+		}
+		rows = append(rows, append(row, strings.Join(wasText, " ")))
+	}
+	return rows
+}
+
+func (v *MemoryDiffViewer) Render(_force bool) {
+	w := v.Window()
+	overlayRows := 0
+	if v.inputActive {
+		overlayRows = 1
+	}
+	v.grid.SetViewport(overlayRows, max(0, w.Height()-overlayRows))
+	v.grid.Render()
+	if v.inputActive {
+		v.inputWidget.Render(false)
+	}
+}
+
+func (v *MemoryDiffViewer) HandleInput(ch int) bool {
+	switch ch {
+	case 'n', 'N':
+		v.pendingCapture = true
+		return true
+	case 'o', 'O':
+		v.inputActive = true
+		v.inputWidget.Activate("")
+		if app := v.App(); app != nil {
+			app.DispatchAction(ActionSetInputFocus, v.handleTextInput)
+		}
+		return true
+	}
+	return v.grid.HandleInput(ch)
+}
+
+func (v *MemoryDiffViewer) handleTextInput(ch int) bool {
+	if ch == 27 || ch == 10 || ch == 13 || ch == KeyEnter() {
+		name := strings.TrimSpace(v.inputWidget.Buffer())
+		if ch != 27 && name != "" {
+			snap, err := LoadSnapshot(name)
+			if err != nil {
+				v.base = nil
+				v.message = err.Error()
+			} else {
+				v.base = &snap
+			}
+		}
+		v.inputActive = false
+		v.inputWidget.Deactivate()
+		v.lastTitle, v.lastRows = "", nil
+		v.liveBase = nil
+		v.nextRPCAt = time.Time{}
+		if app := v.App(); app != nil {
+			app.DispatchAction(ActionSetInputFocus, nil)
+		}
+		return true
+	}
+	v.inputWidget.HandleKey(ch)
+	return true
+}
@@ -0,0 +1,65 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	. "go800mon/a800mon"
+)
+
+// PMGViewer draws the players and missiles found at PMBASE.
+type PMGViewer struct {
+	BaseWindowComponent
+	rpc          *RpcClient
+	grid         *GridWidget
+	lastSnapshot string
+	nextRPCAt    time.Time
+}
+
+func NewPMGViewer(rpc *RpcClient, window *Window) *PMGViewer {
+	grid := NewGridWidget(window)
+	grid.SetSelectionEnabled(false)
+	grid.AddColumn("content", 0, ColorText.Attr(), nil)
+	window.SetVisible(false)
+	return &PMGViewer{
+		BaseWindowComponent: NewBaseWindowComponent(window),
+		rpc:                 rpc,
+		grid:                grid,
+	}
+}
+
+func (p *PMGViewer) Update(ctx context.Context) (bool, error) {
+	now := time.Now()
+	if !p.Window().Visible() || now.Before(p.nextRPCAt) {
+		return false, nil
+	}
+	p.nextRPCAt = now.Add(100 * time.Millisecond)
+	layout, objects, err := p.rpc.PlayerMissiles(ctx)
+	if err != nil {
+		return false, nil
+	}
+	rows := make([][]string, 0, 64)
+	for _, obj := range objects {
+		rows = append(rows, []string{obj.Describe()})
+		for _, line := range obj.Lines() {
+			rows = append(rows, []string{" " + line})
+		}
+	}
+	snapshot := fmt.Sprintf("%v:%v", layout, rows)
+	if snapshot == p.lastSnapshot {
+		return false, nil
+	}
+	p.lastSnapshot = snapshot
+	p.Window().SetTitle(fmt.Sprintf("P/M Graphics %04X", layout.Base))
+	p.grid.SetData(rows)
+	return true, nil
+}
+
+func (p *PMGViewer) Render(_force bool) {
+	p.grid.Render()
+}
+
+func (p *PMGViewer) HandleInput(ch int) bool {
+	return p.grid.HandleInput(ch)
+}
@@ -5,12 +5,14 @@ import (
 	"encoding/binary"
 	"fmt"
 	"hash/fnv"
+	"image/color"
 	"strings"
 	"time"
 
 	. "go800mon/a800mon"
 	"go800mon/internal/atascii"
 	dl "go800mon/internal/displaylist"
+	"go800mon/internal/video"
 )
 
 type ScreenBufferInspector struct {
@@ -23,6 +25,12 @@ type ScreenBufferInspector struct {
 	lastSnapshot   string
 	rpcThrottle    time.Duration
 	nextRPCAt      time.Time
+	charset        bool
+	multicolor     bool
+	font           []byte
+	fontAddr       uint16
+	regs           video.Registers
+	palette        *[256]color.RGBA
 }
 
 type rowRangeIndex struct {
@@ -46,6 +54,22 @@ func NewScreenBufferInspector(rpc *RpcClient, window *Window) *ScreenBufferInspe
 }
 
 func (s *ScreenBufferInspector) HandleInput(ch int) bool {
+	if ch == 'g' || ch == 'G' {
+		s.charset = !s.charset
+		s.Window().SetTagActive("charset", s.charset)
+		if !s.charset {
+			s.Window().SetTitle("Screen Buffer")
+		}
+		s.lastSnapshot = ""
+		s.nextRPCAt = time.Time{}
+		return true
+	}
+	if s.charset && (ch == 'm' || ch == 'M') {
+		s.multicolor = !s.multicolor
+		s.lastSnapshot = ""
+		s.nextRPCAt = time.Time{}
+		return true
+	}
 	if !(ch == int(' ') || ch == int('a') || ch == int('A')) {
 		return s.grid.HandleInput(ch)
 	}
@@ -78,6 +102,9 @@ func (s *ScreenBufferInspector) Update(ctx context.Context) (bool, error) {
 		return changed, nil
 	}
 	s.nextRPCAt = now.Add(s.rpcThrottle)
+	if s.charset {
+		return s.updateCharset(ctx) || changed, nil
+	}
 	mapper := dl.NewMemoryMapper(st.DList, st.DMACTL, 0x400)
 	fetchRanges, rowSlices := mapper.Plan()
 	if len(fetchRanges) == 0 {
@@ -88,7 +115,7 @@ func (s *ScreenBufferInspector) Update(ctx context.Context) (bool, error) {
 		s.lastSnapshot = ""
 		return true, nil
 	}
-	buffer := make([]byte, 0, 8192)
+	ranges := make([]MemoryRange, 0, len(fetchRanges))
 	index := make([]rowRangeIndex, 0, len(fetchRanges))
 	off := 0
 	for _, r := range fetchRanges {
@@ -96,13 +123,17 @@ func (s *ScreenBufferInspector) Update(ctx context.Context) (bool, error) {
 		if ln <= 0 {
 			continue
 		}
-		chunk, err := s.rpc.ReadMemoryChunked(ctx, uint16(r.Start&0xFFFF), ln)
-		if err != nil {
-			return changed, nil
-		}
-		buffer = append(buffer, chunk...)
+		ranges = append(ranges, MemoryRange{Addr: uint16(r.Start & 0xFFFF), Length: ln})
 		index = append(index, rowRangeIndex{s: r.Start, e: r.End, off: off})
-		off += len(chunk)
+		off += ln
+	}
+	chunks, err := s.rpc.ReadMemoryV(ctx, ranges)
+	if err != nil {
+		return changed, nil
+	}
+	buffer := make([]byte, 0, off)
+	for _, chunk := range chunks {
+		buffer = append(buffer, chunk...)
 	}
 	rows := make([]ScreenRow, 0, len(rowSlices))
 	for _, rs := range rowSlices {
@@ -121,6 +152,30 @@ func (s *ScreenBufferInspector) Update(ctx context.Context) (bool, error) {
 	return true, nil
 }
 
+// updateCharset reads the character set at the live CHBASE and the color
+// registers its multicolor view is drawn in.
+func (s *ScreenBufferInspector) updateCharset(ctx context.Context) bool {
+	regs, palette, err := s.rpc.VideoRegisters(ctx)
+	if err != nil {
+		return false
+	}
+	addr := uint16(regs.CHBASE&0xFC) << 8
+	font, err := s.rpc.ReadMemory(ctx, addr, video.CharsetSize)
+	if err != nil || len(font) != video.CharsetSize {
+		return false
+	}
+	snapshot := fmt.Sprintf("charset:%04X:%x:%02X:%x", addr, font, regs.COLBK, regs.COLPF)
+	if s.lastSnapshot == snapshot {
+		return false
+	}
+	s.lastSnapshot = snapshot
+	s.font = font
+	s.fontAddr = addr
+	s.regs = regs
+	s.palette = palette
+	return true
+}
+
 func readRow(buffer []byte, index []rowRangeIndex, addr, ln int) []byte {
 	if ln <= 0 {
 		return nil
@@ -158,6 +213,10 @@ func readRow(buffer []byte, index []rowRangeIndex, addr, ln int) []byte {
 func (s *ScreenBufferInspector) Render(_force bool) {
 	st := State()
 	w := s.Window()
+	if s.charset {
+		s.renderCharset()
+		return
+	}
 	contentWidth := w.Width() - 8
 	if contentWidth < 0 {
 		contentWidth = 0
@@ -203,6 +262,59 @@ func (s *ScreenBufferInspector) Render(_force bool) {
 	s.grid.Render()
 }
 
+// renderCharset lists the glyphs in blocks of 8 rows, as many per line as
+// the window fits.
+func (s *ScreenBufferInspector) renderCharset() {
+	gridRows := make([][]string, 0, 8*16)
+	perLine := max(1, (s.Window().Width()-5)/9)
+	if len(s.font) == video.CharsetSize {
+		for code := 0; code < 128; code += perLine {
+			var lines [8][]string
+			for c := code; c < min(128, code+perLine); c++ {
+				glyph := video.GlyphText(s.font, byte(c), s.multicolor)
+				for y := range lines {
+					lines[y] = append(lines[y], glyph[y])
+				}
+			}
+			for y, line := range lines {
+				label := "    "
+				if y == 0 {
+					label = fmt.Sprintf("$%02X ", code)
+				}
+				gridRows = append(gridRows, []string{label, strings.Join(line, " ")})
+			}
+		}
+	}
+	s.Window().SetTitle(fmt.Sprintf("Charset %04X", s.fontAddr))
+	s.grid.SetData(gridRows)
+	s.grid.SetSelectedRow(nil)
+	s.grid.Render()
+	if s.multicolor && len(s.font) == video.CharsetSize && s.palette != nil {
+		s.paintMulticolor(perLine)
+	}
+}
+
+// paintMulticolor redraws the visible glyph pixels as blocks in the terminal
+// colors nearest to COLBK and COLPF0-2, as screen --png colors them.
+func (s *ScreenBufferInspector) paintMulticolor(perLine int) {
+	w := s.Window()
+	for y := 0; y < w.Height(); y++ {
+		row := s.grid.Offset() + y
+		code, line := row/8*perLine, row%8
+		if code >= 128 {
+			break
+		}
+		for i := 0; i < perLine && code+i < 128; i++ {
+			colors := s.regs.GlyphColors(s.font, byte(code+i), true)
+			for x := 0; x < 8; x += 2 {
+				rgb := s.palette[colors[line][x]]
+				w.Cursor(4+i*9+x, y)
+				w.Print("██", RGBAttr(rgb.R, rgb.G, rgb.B), false)
+			}
+		}
+	}
+}
+
 func renderScreenText(data []byte, useATASCII bool) string {
 	if len(data) == 0 {
 		return ""
@@ -18,6 +18,7 @@ type StatusUpdater struct {
 	forceRefresh    bool
 	capsSynced      bool
 	lastCapsAttempt time.Time
+	lastCartPoll    time.Time
 }
 
 func NewStatusUpdater(rpc *RpcClient, dispatcher *ActionDispatcher, pausedInterval, runningInterval time.Duration) *StatusUpdater {
@@ -60,12 +61,19 @@ func (s *StatusUpdater) Tick(ctx context.Context) (bool, error) {
 		st.EmuMS != status.EmuMS ||
 		st.ResetMS != status.ResetMS ||
 		st.Crashed != status.Crashed ||
-		st.StateSeq != status.StateSeq
+		st.StateSeq != status.StateSeq ||
+		st.MachineType != status.MachineType
 	if changed {
 		_ = s.dispatcher.Dispatch(ActionSetStatus, status)
 	}
 	if changed || forced {
 		s.updateCPU(ctx)
+		s.updatePORTB(ctx, status.MachineType, st.PORTB)
+	}
+	// Bank switches do not show in the status, so poll the slots slowly.
+	if forced || time.Since(s.lastCartPoll) >= time.Second {
+		s.lastCartPoll = time.Now()
+		s.updateCart(ctx, st.Cart)
 	}
 	needCaps := hadError || !s.capsSynced
 	if needCaps {
@@ -88,7 +96,7 @@ func (s *StatusUpdater) updateCPU(ctx context.Context) {
 	}
 	cpuDisasm := ""
 	if code, err := s.rpc.ReadMemory(ctx, cpu.PC, 3); err == nil {
-		cpuDisasm = disasm.DisasmOne(cpu.PC, code)
+		cpuDisasm = disasm.DisasmOne(cpu.PC, code, State().IllegalOpcodes)
 	}
 	_ = s.dispatcher.Dispatch(
 		ActionSetCPU,
@@ -96,6 +104,27 @@ func (s *StatusUpdater) updateCPU(ctx context.Context) {
 	)
 }
 
+// updatePORTB tracks the extended RAM bank selection on machines that have
+// one.
+func (s *StatusUpdater) updatePORTB(ctx context.Context, machineType, prev byte) {
+	if BankModelFor(machineType).Banks == 0 {
+		return
+	}
+	pia, err := s.rpc.PIAState(ctx)
+	if err != nil || pia.PORTB == prev {
+		return
+	}
+	_ = s.dispatcher.Dispatch(ActionSetPORTB, pia.PORTB)
+}
+
+func (s *StatusUpdater) updateCart(ctx context.Context, prev CartState) {
+	cart, err := s.rpc.CartrigeState(ctx)
+	if err != nil || cart == prev {
+		return
+	}
+	_ = s.dispatcher.Dispatch(ActionSetCart, cart)
+}
+
 func (s *StatusUpdater) syncRPCError() {
 	err := s.rpc.LastError()
 	text := ""
@@ -3,14 +3,18 @@ package monitor
 import (
 	"context"
 	"fmt"
+	"time"
 
 	. "go800mon/a800mon"
+	"go800mon/internal/cartridge"
 )
 
 const (
 	topbarTitle      = "Atari800 Monitor"
 	topbarCopyright  = "(c) 2026 Marcin Nowak"
 	topbarRightWidth = 43
+	// noticeDuration is how long a notice replaces the copyright.
+	noticeDuration = 5 * time.Second
 )
 
 type TopBar struct {
@@ -29,7 +33,7 @@ func NewTopBar(window *Window) *TopBar {
 
 func (t *TopBar) Update(_ctx context.Context) (bool, error) {
 	st := State()
-	snap := fmt.Sprintf("%s|%t|%d|%d|%d|%t", st.LastRPCError, st.Crashed, st.EmuMS, st.ResetMS, st.MonitorFrameTimeMS, st.UIFrozen)
+	snap := fmt.Sprintf("%s|%t|%d|%d|%d|%t|%v|%d|%02X|%s", st.LastRPCError, st.Crashed, st.EmuMS, st.ResetMS, st.MonitorFrameTimeMS, st.UIFrozen, st.Cart, st.MachineType, st.PORTB, activeNotice(st))
 	if t.lastSnapshot == snap {
 		return false, nil
 	}
@@ -46,10 +50,38 @@ func (t *TopBar) Render(_force bool) {
 		w.Print(" "+st.LastRPCError+" ", ColorError.Attr(), false)
 		w.FillToEOL(' ', ColorError.Attr())
 	} else {
-		w.Print(topbarTitle+"     "+topbarCopyright, ColorTopbar.Attr(), false)
+		left := topbarTitle + "     "
+		w.Print(left, ColorTopbar.Attr(), false)
+		if notice := activeNotice(st); notice != "" {
+			color := ColorText
+			if st.NoticeError {
+				color = ColorError
+			}
+			w.Print(" "+notice+" ", color.Attr(), false)
+			left += " " + notice + " "
+		} else {
+			w.Print(topbarCopyright, ColorTopbar.Attr(), false)
+			left += topbarCopyright
+		}
 		if st.UIFrozen {
 			w.Print("   ", ColorTopbar.Attr(), false)
 			w.Print(" FREEZE ", ColorError.Attr(), false)
+			left += "    FREEZE "
+		}
+		if bank := BankModelFor(st.MachineType).Label(st.PORTB); bank != "" {
+			w.Print("   ", ColorTopbar.Attr(), false)
+			w.Print(" "+bank+" ", ColorText.Attr(), false)
+			left += "    " + bank + " "
+		}
+		if label := cartLabel(st.Cart.Main); label != "" {
+			room := w.Width() - topbarRightWidth - len(left) - 4
+			if room > 8 {
+				if len(label) > room {
+					label = label[:room]
+				}
+				w.Print("   ", ColorTopbar.Attr(), false)
+				w.Print(" "+label+" ", ColorText.Attr(), false)
+			}
 		}
 		w.FillToEOL(' ', ColorTopbar.Attr())
 	}
@@ -71,6 +103,28 @@ func (t *TopBar) Render(_force bool) {
 	}
 }
 
+// activeNotice returns the latest notice until it expires.
+func activeNotice(st AppStateData) string {
+	if st.Notice == "" || time.Since(st.NoticeAt) > noticeDuration {
+		return ""
+	}
+	return st.Notice
+}
+
+// cartLabel names the cartridge in the main slot with its selected bank.
+func cartLabel(slot CartSlotState) string {
+	if slot.Present == 0 {
+		return ""
+	}
+	label := "CART " + cartridge.Name(int(slot.Type))
+	if t, ok := cartridge.Lookup(int(slot.Type)); ok {
+		if bank := t.DescribeBank(slot.State); bank != "" {
+			label += " " + bank
+		}
+	}
+	return label
+}
+
 func crashLabel(crashed bool) string {
 	if crashed {
 		return " CRASH "
@@ -18,6 +18,7 @@ type WatchersViewer struct {
 	pending      *WatcherRow
 	inputActive  bool
 	lastSnapshot string
+	symbolsSeq   uint64
 	searchInput  *InputWidget
 }
 
@@ -45,48 +46,28 @@ func NewWatchersViewer(rpc *RpcClient, window *Window) *WatchersViewer {
 }
 
 func (v *WatchersViewer) Update(ctx context.Context) (bool, error) {
-	rows := make([]WatcherRow, 0, len(v.rows))
+	if seq := State().SymbolsSeq; seq != v.symbolsSeq {
+		v.symbolsSeq = seq
+		v.lastSnapshot = ""
+	}
+	ranges := make([]MemoryRange, 0, len(v.rows)+1)
 	for _, row := range v.rows {
-		value := row.Value
-		nextValue := row.NextValue
-		data, err := v.rpc.ReadMemory(ctx, row.Addr, 2)
-		if err != nil {
-			return false, nil
-		}
-		if len(data) > 0 {
-			value = data[0]
-		}
-		if len(data) > 1 {
-			nextValue = data[1]
-		}
-		rows = append(rows, WatcherRow{
-			Addr:      row.Addr,
-			Value:     value,
-			NextValue: nextValue,
-			Comment:   atari.LookupSymbol(row.Addr),
-		})
+		ranges = append(ranges, MemoryRange{Addr: row.Addr, Length: 2})
+	}
+	if v.pending != nil {
+		ranges = append(ranges, MemoryRange{Addr: v.pending.Addr, Length: 2})
+	}
+	values, err := v.rpc.ReadMemoryV(ctx, ranges)
+	if err != nil {
+		return false, nil
+	}
+	rows := make([]WatcherRow, 0, len(v.rows))
+	for i, row := range v.rows {
+		rows = append(rows, refreshWatcherRow(row, values[i]))
 	}
-
 	var pending *WatcherRow
 	if v.pending != nil {
-		value := v.pending.Value
-		nextValue := v.pending.NextValue
-		data, err := v.rpc.ReadMemory(ctx, v.pending.Addr, 2)
-		if err != nil {
-			return false, nil
-		}
-		if len(data) > 0 {
-			value = data[0]
-		}
-		if len(data) > 1 {
-			nextValue = data[1]
-		}
-		p := WatcherRow{
-			Addr:      v.pending.Addr,
-			Value:     value,
-			NextValue: nextValue,
-			Comment:   atari.LookupSymbol(v.pending.Addr),
-		}
+		p := refreshWatcherRow(*v.pending, values[len(v.rows)])
 		pending = &p
 	}
 
@@ -169,6 +150,15 @@ func (v *WatchersViewer) HandleInput(ch int) bool {
 		return true
 	}
 
+	if ch == 'x' || ch == 'X' {
+		if idx, ok := v.grid.SelectedRow(); ok && idx < len(v.rows) {
+			if app := v.App(); app != nil {
+				app.DispatchAction(ActionSetXrefsAddr, v.rows[idx].Addr)
+			}
+		}
+		return true
+	}
+
 	return false
 }
 
@@ -284,3 +274,20 @@ func buildWatchersSnapshot(rows []WatcherRow, pending *WatcherRow, selected *int
 	parts = append(parts, fmt.Sprintf("input:%t", inputActive))
 	return strings.Join(parts, "|")
 }
+
+func refreshWatcherRow(row WatcherRow, data []byte) WatcherRow {
+	value := row.Value
+	nextValue := row.NextValue
+	if len(data) > 0 {
+		value = data[0]
+	}
+	if len(data) > 1 {
+		nextValue = data[1]
+	}
+	return WatcherRow{
+		Addr:      row.Addr,
+		Value:     value,
+		NextValue: nextValue,
+		Comment:   atari.LookupSymbol(row.Addr),
+	}
+}
@@ -0,0 +1,149 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+
+	. "go800mon/a800mon"
+	"go800mon/internal/disasm"
+)
+
+// XrefsViewer is a popup listing the instructions that reference
+// State().XrefsAddr. It opens over the window focused when the address was
+// set; Enter jumps to the selected reference.
+type XrefsViewer struct {
+	BaseWindowComponent
+	rpc    *RpcClient
+	screen *Screen
+	grid   *GridWidget
+	onJump func(addr uint16)
+	// disasm supplies the flow setting and entry marks of the Disassembler.
+	disasm *DisassemblyViewer
+	host   *Window
+	target uint16
+	refs   []disasm.Xref
+}
+
+func NewXrefsViewer(rpc *RpcClient, window *Window, screen *Screen) *XrefsViewer {
+	grid := NewGridWidget(window)
+	grid.SetColumnGap(1)
+	grid.AddColumn("kind", 6, ColorComment.Attr(), nil)
+	grid.AddColumn("address", 5, ColorAddress.Attr(), nil)
+	grid.AddColumn("instruction", 0, ColorText.Attr(), nil)
+	window.SetVisible(false)
+	v := &XrefsViewer{
+		BaseWindowComponent: NewBaseWindowComponent(window),
+		rpc:                 rpc,
+		screen:              screen,
+		grid:                grid,
+	}
+	window.WindowCallbacks(nil, func() {
+		if app := v.App(); app != nil {
+			app.DispatchAction(ActionSetXrefsAddr, nil)
+		}
+	})
+	return v
+}
+
+func (v *XrefsViewer) Update(ctx context.Context) (bool, error) {
+	st := State()
+	w := v.Window()
+	if st.XrefsAddr == nil {
+		if !w.Visible() {
+			return false, nil
+		}
+		w.SetVisible(false)
+		if v.host != nil && v.screen.Focused() == w {
+			v.screen.Focus(v.host)
+		}
+		if app := v.App(); app != nil {
+			app.RebuildScreen()
+		}
+		return true, nil
+	}
+	if w.Visible() && v.target == *st.XrefsAddr {
+		return false, nil
+	}
+	decoded, err := v.decode(ctx, st.IllegalOpcodes)
+	if err != nil {
+		return false, nil
+	}
+	v.target = *st.XrefsAddr
+	v.refs = disasm.BuildXrefs(decoded)[v.target]
+	rows := make([][]string, 0, len(v.refs))
+	for _, ref := range v.refs {
+		rows = append(rows, []string{ref.Kind, formatHex16(ref.Ins.Addr) + ":", ref.Ins.AsmText})
+	}
+	v.grid.SetData(rows)
+	first := 0
+	v.grid.SetSelectedRow(&first)
+	w.SetTitle(fmt.Sprintf("Xrefs to %04X (%d)", v.target, len(v.refs)))
+	if !w.Visible() {
+		v.host = v.screen.Focused()
+		w.SetVisible(true)
+		v.fitToHost()
+		v.screen.Focus(w)
+	}
+	return true, nil
+}
+
+// decode disassembles all memory linearly, or along the code/data map with
+// the Disassembler's marks while its flow view is on.
+func (v *XrefsViewer) decode(ctx context.Context, illegal bool) ([]disasm.DecodedInstruction, error) {
+	if v.disasm != nil && v.disasm.flowEnabled {
+		codeFlow, mem, err := v.rpc.AnalyzeFlow(ctx, v.disasm.marks, illegal)
+		if err != nil {
+			return nil, err
+		}
+		return codeFlow.Decode(0, mem), nil
+	}
+	chunks, err := v.rpc.ReadMemoryV(ctx, []MemoryRange{{Addr: 0, Length: 0x10000}})
+	if err != nil {
+		return nil, err
+	}
+	return disasm.Decode(0, chunks[0], illegal), nil
+}
+
+// fitToHost places the popup inside the window it was opened from.
+func (v *XrefsViewer) fitToHost() {
+	if v.host == nil || !v.Window().Visible() {
+		return
+	}
+	h := v.host
+	v.Window().Reshape(h.X()+2, h.Y()+1, max(12, h.OuterWidth()-4), max(4, h.Height()))
+}
+
+func (v *XrefsViewer) Render(_force bool) {
+	w := v.Window()
+	w.Redraw()
+	if len(v.refs) == 0 {
+		w.Cursor(0, 0)
+		w.Print("No references.", ColorText.Attr(), false)
+		w.ClearToBottom()
+		return
+	}
+	v.grid.Render()
+}
+
+func (v *XrefsViewer) HandleInput(ch int) bool {
+	if !v.Window().Visible() {
+		return false
+	}
+	if ch == 27 || ch == 'x' || ch == 'X' {
+		if app := v.App(); app != nil {
+			app.DispatchAction(ActionSetXrefsAddr, nil)
+		}
+		return true
+	}
+	if ch == 10 || ch == 13 || ch == KeyEnter() {
+		idx, ok := v.grid.SelectedRow()
+		if ok && idx < len(v.refs) && v.onJump != nil {
+			if app := v.App(); app != nil {
+				app.DispatchAction(ActionSetXrefsAddr, nil)
+			}
+			v.onJump(v.refs[idx].Ins.Addr)
+		}
+		return true
+	}
+	return v.grid.HandleInput(ch)
+}
@@ -0,0 +1,26 @@
+package a800mon
+
+import (
+	"context"
+
+	"go800mon/internal/video"
+)
+
+// PlayerMissiles locates the P/M area from PMBASE and DMACTL and decodes the
+// players and missiles with their live GTIA registers.
+func (r *RpcClient) PlayerMissiles(ctx context.Context) (video.PMLayout, []video.PMObject, error) {
+	antic, err := r.ANTICState(ctx)
+	if err != nil {
+		return video.PMLayout{}, nil, err
+	}
+	gtia, err := r.GTIAState(ctx)
+	if err != nil {
+		return video.PMLayout{}, nil, err
+	}
+	layout := video.NewPMLayout(antic.PMBASE, antic.DMACTL)
+	area, err := r.ReadMemoryChunked(ctx, layout.Base, layout.Size())
+	if err != nil {
+		return video.PMLayout{}, nil, err
+	}
+	return layout, video.PMObjects(area, layout, gtia, antic.DMACTL), nil
+}
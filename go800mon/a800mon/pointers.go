@@ -0,0 +1,243 @@
+package a800mon
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"go800mon/internal/memorymap"
+)
+
+// PointerChainLimit caps the chains a scan keeps, as low max offsets on a
+// busy machine easily match thousands of words.
+const PointerChainLimit = 10000
+
+// PointerChain reaches a target from a fixed base: read the word at Base,
+// add Offsets[0], read the word there, add Offsets[1], and so on. Hits
+// counts the snapshots in which the chain led to the target.
+type PointerChain struct {
+	Base    uint16
+	Offsets []int
+	Hits    int
+}
+
+// Depth is the number of pointers the chain follows.
+func (c PointerChain) Depth() int {
+	return len(c.Offsets)
+}
+
+// Expr formats the chain as an address expression, e.g. [[$0080]+$02]-$05.
+func (c PointerChain) Expr() string {
+	expr := fmt.Sprintf("$%04X", c.Base)
+	for _, off := range c.Offsets {
+		expr = "[" + expr + "]"
+		switch {
+		case off > 0:
+			expr += fmt.Sprintf("+$%02X", off)
+		case off < 0:
+			expr += fmt.Sprintf("-$%02X", -off)
+		}
+	}
+	return expr
+}
+
+// Resolve follows the chain through mem, a full 64K image.
+func (c PointerChain) Resolve(mem []byte) uint16 {
+	addr := c.Base
+	for _, off := range c.Offsets {
+		addr = uint16(int(readWord(mem, addr)) + off)
+	}
+	return addr
+}
+
+func (c PointerChain) offsetSum() int {
+	sum := 0
+	for _, off := range c.Offsets {
+		if off < 0 {
+			off = -off
+		}
+		sum += off
+	}
+	return sum
+}
+
+// PointerScanner finds chains of little-endian words that lead to a target
+// address, then keeps score as the target moves between snapshots.
+type PointerScanner struct {
+	depth     int
+	maxOffset int
+	snapshots int
+	truncated bool
+	chains    []PointerChain
+	reader    func(start uint16, length int) ([]byte, error)
+}
+
+func NewPointerScanner(depth, maxOffset int) (*PointerScanner, error) {
+	if depth < 1 {
+		return nil, errors.New("Pointer depth must be at least 1.")
+	}
+	if maxOffset < 0 || maxOffset > 0xFFFF {
+		return nil, errors.New("Max offset must be between 0 and $FFFF.")
+	}
+	return &PointerScanner{depth: depth, maxOffset: maxOffset}, nil
+}
+
+func (s *PointerScanner) BindReader(reader func(start uint16, length int) ([]byte, error)) {
+	s.reader = reader
+}
+
+// Scan reads memory and collects every chain of up to depth pointers that
+// ends within maxOffset of target. Each address is used as a base once, at
+// the shallowest depth it reaches the target, which also keeps chains from
+// looping. Words in hardware registers are not pointers and are skipped.
+func (s *PointerScanner) Scan(target uint16) (int, error) {
+	mem, err := s.read()
+	if err != nil {
+		return 0, err
+	}
+	var io [0x10000]bool
+	for _, area := range memorymap.Areas {
+		if area.Kind == memorymap.AreaIO {
+			for addr := int(area.Start); addr <= int(area.End); addr++ {
+				io[addr] = true
+			}
+		}
+	}
+	index := make([][]uint16, 0x10000)
+	for addr := 0; addr < 0xFFFF; addr++ {
+		if io[addr] || io[addr+1] {
+			continue
+		}
+		w := readWord(mem, uint16(addr))
+		index[w] = append(index[w], uint16(addr))
+	}
+	seen := map[uint16]bool{target: true}
+	s.chains = nil
+	s.truncated = false
+	s.snapshots = 1
+	level := []PointerChain{{Base: target}}
+scan:
+	for d := 0; d < s.depth && len(level) > 0; d++ {
+		var next []PointerChain
+		for _, node := range level {
+			// Pointer values within maxOffset of the node, nearest first.
+			for i := 0; i <= 2*s.maxOffset; i++ {
+				v := int(node.Base) + (i+1)/2
+				if i%2 == 1 {
+					v = int(node.Base) - (i+1)/2
+				}
+				if v < 0 || v > 0xFFFF {
+					continue
+				}
+				for _, addr := range index[v] {
+					if seen[addr] {
+						continue
+					}
+					if len(s.chains) >= PointerChainLimit {
+						s.truncated = true
+						break scan
+					}
+					seen[addr] = true
+					offsets := append([]int{int(node.Base) - v}, node.Offsets...)
+					chain := PointerChain{Base: addr, Offsets: offsets, Hits: 1}
+					s.chains = append(s.chains, chain)
+					next = append(next, chain)
+				}
+			}
+		}
+		level = next
+	}
+	s.sortChains()
+	return len(s.chains), nil
+}
+
+// Snapshot reads memory again and scores each chain that still leads to
+// target, which may have moved since the last snapshot.
+func (s *PointerScanner) Snapshot(target uint16) (int, error) {
+	if s.snapshots == 0 {
+		return 0, errors.New("Pointer scan has not been started.")
+	}
+	mem, err := s.read()
+	if err != nil {
+		return 0, err
+	}
+	s.snapshots++
+	for i := range s.chains {
+		if s.chains[i].Resolve(mem) == target {
+			s.chains[i].Hits++
+		}
+	}
+	s.sortChains()
+	return s.StableCount(), nil
+}
+
+// sortChains ranks chains by hits, then fewer pointers, smaller offsets and
+// lower base address.
+func (s *PointerScanner) sortChains() {
+	sort.SliceStable(s.chains, func(i, j int) bool {
+		a, b := s.chains[i], s.chains[j]
+		if a.Hits != b.Hits {
+			return a.Hits > b.Hits
+		}
+		if a.Depth() != b.Depth() {
+			return a.Depth() < b.Depth()
+		}
+		if a.offsetSum() != b.offsetSum() {
+			return a.offsetSum() < b.offsetSum()
+		}
+		return a.Base < b.Base
+	})
+}
+
+// Snapshots is the number of memory reads scored so far.
+func (s *PointerScanner) Snapshots() int {
+	return s.snapshots
+}
+
+// Truncated reports whether the last scan stopped at PointerChainLimit.
+func (s *PointerScanner) Truncated() bool {
+	return s.truncated
+}
+
+func (s *PointerScanner) ChainCount() int {
+	return len(s.chains)
+}
+
+// StableCount is the number of chains that hit in every snapshot.
+func (s *PointerScanner) StableCount() int {
+	n := 0
+	for _, c := range s.chains {
+		if c.Hits == s.snapshots {
+			n++
+		}
+	}
+	return n
+}
+
+// Chains returns up to limit chains in rank order, all of them for limit <= 0.
+func (s *PointerScanner) Chains(limit int) []PointerChain {
+	if limit <= 0 || limit > len(s.chains) {
+		limit = len(s.chains)
+	}
+	out := make([]PointerChain, limit)
+	copy(out, s.chains[:limit])
+	return out
+}
+
+func (s *PointerScanner) read() ([]byte, error) {
+	if s.reader == nil {
+		return nil, errors.New("Pointer scanner reader is not bound.")
+	}
+	data, err := s.reader(0, 0x10000)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < 0x10000 {
+		return nil, errors.New("Pointer scanner read returned too few bytes.")
+	}
+	return data[:0x10000], nil
+}
+
+func readWord(mem []byte, addr uint16) uint16 {
+	return uint16(mem[addr]) | uint16(mem[addr+1])<<8
+}
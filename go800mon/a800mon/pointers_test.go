@@ -0,0 +1,28 @@
+package a800mon
+
+import "testing"
+
+func TestPointerScannerScan(t *testing.T) {
+	mem := make([]byte, 0x10000)
+	mem[0x0080], mem[0x0081] = 0x00, 0x30 // $0080 -> $3000
+	mem[0x0600], mem[0x0601] = 0x80, 0x00 // $0600 -> $0080
+	mem[0xD200], mem[0xD201] = 0x02, 0x30 // POKEY register noise
+	s, err := NewPointerScanner(2, 8)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.BindReader(func(start uint16, length int) ([]byte, error) { return mem, nil })
+	if _, err := s.Scan(0x3005); err != nil {
+		t.Fatal(err)
+	}
+	var exprs []string
+	for _, c := range s.Chains(0) {
+		exprs = append(exprs, c.Expr())
+		if c.Base >= 0xD000 && c.Base <= 0xD7FF {
+			t.Errorf("chain %s starts in hardware registers", c.Expr())
+		}
+	}
+	if len(exprs) != 2 || exprs[0] != "[$0080]+$05" || exprs[1] != "[[$0600]]+$05" {
+		t.Fatalf("chains = %v", exprs)
+	}
+}
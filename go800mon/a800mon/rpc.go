@@ -17,6 +17,7 @@ type CpuHistoryEntry = irpc.HistoryEntry
 type BreakpointCondition = irpc.BreakpointCondition
 type BreakpointList = irpc.BreakpointList
 type CommandError = irpc.CommandError
+type MemoryRange = irpc.Range
 
 const (
 	CmdPing            = irpc.CmdPing
@@ -96,6 +97,10 @@ func (r *RpcClient) ReadMemoryChunked(ctx context.Context, addr uint16, length i
 	return r.inner.ReadMemoryChunked(ctx, addr, length, 0x400)
 }
 
+func (r *RpcClient) ReadMemoryV(ctx context.Context, ranges []MemoryRange) ([][]byte, error) {
+	return r.inner.ReadMemoryV(ctx, ranges)
+}
+
 func (r *RpcClient) WriteMemory(ctx context.Context, addr uint16, data []byte) error {
 	return r.inner.WriteMemory(ctx, addr, data)
 }
@@ -176,6 +181,10 @@ func (r *RpcClient) BPSetEnabled(ctx context.Context, enabled bool) (bool, error
 	return r.inner.BPSetEnabled(ctx, enabled)
 }
 
+func (r *RpcClient) BLine(ctx context.Context, line *uint16) (uint16, byte, error) {
+	return r.inner.BLine(ctx, line)
+}
+
 func (r *RpcClient) BPList(ctx context.Context) (BreakpointList, error) {
 	return r.inner.BPList(ctx)
 }
@@ -0,0 +1,73 @@
+package a800mon
+
+import (
+	"context"
+	"image"
+	"image/color"
+
+	dl "go800mon/internal/displaylist"
+	"go800mon/internal/video"
+)
+
+// RenderScreen draws the current display list with the live ANTIC and GTIA
+// registers, reading only the screen rows and the character set. The palette
+// follows the emulated TV system.
+func (r *RpcClient) RenderScreen(ctx context.Context) (*image.RGBA, error) {
+	start, err := r.ReadVector(ctx, dl.DLPTRSAddr)
+	if err != nil {
+		return nil, err
+	}
+	dump, err := r.ReadDisplayListAt(ctx, start)
+	if err != nil {
+		return nil, err
+	}
+	regs, palette, err := r.VideoRegisters(ctx)
+	if err != nil {
+		return nil, err
+	}
+	dlist := dl.Decode(start, dump)
+	fetch, _ := dl.NewMemoryMapper(dlist, regs.DMACTL, 4096).Plan()
+	ranges := []MemoryRange{{Addr: uint16(regs.CHBASE&0xFC) << 8, Length: video.CharsetSize}}
+	for _, f := range fetch {
+		ranges = append(ranges, MemoryRange{Addr: uint16(f.Start), Length: f.End - f.Start})
+	}
+	chunks, err := r.ReadMemoryV(ctx, ranges)
+	if err != nil {
+		return nil, err
+	}
+	mem := make([]byte, 0x10000)
+	for i, chunk := range chunks {
+		copy(mem[ranges[i].Addr:], chunk)
+	}
+	return video.Render(dlist, regs, mem, palette), nil
+}
+
+// VideoRegisters returns the live ANTIC and GTIA registers used for rendering
+// and the palette of the emulated TV system.
+func (r *RpcClient) VideoRegisters(ctx context.Context) (video.Registers, *[256]color.RGBA, error) {
+	antic, err := r.ANTICState(ctx)
+	if err != nil {
+		return video.Registers{}, nil, err
+	}
+	gtia, err := r.GTIAState(ctx)
+	if err != nil {
+		return video.Registers{}, nil, err
+	}
+	info, err := r.Sysinfo(ctx)
+	if err != nil {
+		return video.Registers{}, nil, err
+	}
+	regs := video.Registers{
+		DMACTL: antic.DMACTL,
+		CHACTL: antic.CHACTL,
+		CHBASE: antic.CHBASE,
+		PRIOR:  gtia.PRIOR,
+		COLBK:  gtia.COLBK,
+		COLPF:  gtia.COLPF,
+		COLPM:  gtia.COLPM,
+	}
+	if info.TVPAL {
+		return regs, &video.PALPalette, nil
+	}
+	return regs, &video.NTSCPalette, nil
+}
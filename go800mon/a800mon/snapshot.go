@@ -0,0 +1,140 @@
+package a800mon
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// MemorySnapshot is a capture of memory ranges together with the emulator
+// state at the time it was taken.
+type MemorySnapshot struct {
+	Name     string
+	Time     time.Time
+	StateSeq uint64
+	CPU      CPUState
+	Ranges   []SnapshotRange
+}
+
+type SnapshotRange struct {
+	Addr uint16
+	Data []byte
+}
+
+// ByteChange is a byte that differs between two snapshots.
+type ByteChange struct {
+	Addr uint16
+	Old  byte
+	New  byte
+}
+
+// CaptureSnapshot reads ranges along with the status and CPU state.
+func (r *RpcClient) CaptureSnapshot(ctx context.Context, name string, ranges []MemoryRange) (MemorySnapshot, error) {
+	status, err := r.Status(ctx)
+	if err != nil {
+		return MemorySnapshot{}, err
+	}
+	cpu, err := r.CPUState(ctx)
+	if err != nil {
+		return MemorySnapshot{}, err
+	}
+	chunks, err := r.ReadMemoryV(ctx, ranges)
+	if err != nil {
+		return MemorySnapshot{}, err
+	}
+	snap := MemorySnapshot{Name: name, Time: time.Now(), StateSeq: status.StateSeq, CPU: cpu}
+	for i, rg := range ranges {
+		snap.Ranges = append(snap.Ranges, SnapshotRange{Addr: rg.Addr, Data: chunks[i]})
+	}
+	return snap, nil
+}
+
+// MemoryRanges returns the ranges s covers, to capture the same memory again.
+func (s MemorySnapshot) MemoryRanges() []MemoryRange {
+	out := make([]MemoryRange, len(s.Ranges))
+	for i, rg := range s.Ranges {
+		out[i] = MemoryRange{Addr: rg.Addr, Length: len(rg.Data)}
+	}
+	return out
+}
+
+// Size is the number of bytes captured.
+func (s MemorySnapshot) Size() int {
+	n := 0
+	for _, rg := range s.Ranges {
+		n += len(rg.Data)
+	}
+	return n
+}
+
+// Diff lists the bytes that changed from s to next, in the order of next's
+// ranges. Addresses only one of the snapshots covers are skipped.
+func (s MemorySnapshot) Diff(next MemorySnapshot) []ByteChange {
+	var old [0x10000]byte
+	var covered [0x10000]bool
+	for _, rg := range s.Ranges {
+		for i, b := range rg.Data {
+			addr := rg.Addr + uint16(i)
+			old[addr], covered[addr] = b, true
+		}
+	}
+	var out []ByteChange
+	for _, rg := range next.Ranges {
+		for i, b := range rg.Data {
+			addr := rg.Addr + uint16(i)
+			if covered[addr] && old[addr] != b {
+				out = append(out, ByteChange{Addr: addr, Old: old[addr], New: b})
+			}
+		}
+	}
+	return out
+}
+
+// SnapshotPath maps a snapshot name to its file. Bare names are kept in the
+// a800mon/snapshots directory of the user config dir; names with a directory
+// or a .json extension are paths.
+func SnapshotPath(name string) (string, error) {
+	if strings.ContainsRune(name, filepath.Separator) || filepath.Ext(name) == ".json" {
+		return name, nil
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "a800mon", "snapshots", name+".json"), nil
+}
+
+// Save writes s to the file of its name and returns the path.
+func (s MemorySnapshot) Save() (string, error) {
+	path, err := SnapshotPath(s.Name)
+	if err != nil {
+		return "", err
+	}
+	data, err := json.Marshal(s)
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return "", err
+	}
+	return path, os.WriteFile(path, data, 0o644)
+}
+
+func LoadSnapshot(name string) (MemorySnapshot, error) {
+	path, err := SnapshotPath(name)
+	if err != nil {
+		return MemorySnapshot{}, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return MemorySnapshot{}, err
+	}
+	var snap MemorySnapshot
+	if err := json.Unmarshal(data, &snap); err != nil {
+		return MemorySnapshot{}, err
+	}
+	return snap, nil
+}
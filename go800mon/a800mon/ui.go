@@ -17,6 +17,8 @@ static int g_key_f(int n) { return KEY_F(n); }
 static int g_key_resize() { return KEY_RESIZE; }
 static int g_key_up() { return KEY_UP; }
 static int g_key_down() { return KEY_DOWN; }
+static int g_key_left() { return KEY_LEFT; }
+static int g_key_right() { return KEY_RIGHT; }
 static int g_key_ppage() { return KEY_PPAGE; }
 static int g_key_npage() { return KEY_NPAGE; }
 static int g_key_home() { return KEY_HOME; }
@@ -1024,6 +1026,10 @@ func (g *GridWidget) SelectedRow() (int, bool) {
 	return g.selectedRow, true
 }
 
+func (g *GridWidget) Offset() int {
+	return g.offset
+}
+
 func (g *GridWidget) SetOffset(offset int) {
 	maxOffset := g.maxOffset()
 	value := offset
@@ -1732,6 +1738,34 @@ func initColorPairs() {
 	C.init_pair(6, C.COLOR_YELLOW, C.COLOR_BLACK)
 	C.init_pair(7, C.COLOR_WHITE, C.COLOR_BLACK)
 	C.init_pair(8, C.COLOR_BLUE, C.COLOR_BLACK)
+	// Pairs 9-16 draw the eight basic colors on black for RGBAttr.
+	for i := 0; i < 8; i++ {
+		C.init_pair(C.short(9+i), C.short(i), C.COLOR_BLACK)
+	}
+}
+
+// RGBAttr returns the attribute of the basic terminal color closest to the
+// given RGB value, bold for bright and dim for dark shades.
+func RGBAttr(r, g, b uint8) int {
+	hi := max(r, max(g, b))
+	if hi < 0x30 {
+		return int(C.g_color_pair(9))
+	}
+	// The curses color numbers are a red/green/blue bit mask.
+	idx := 0
+	for i, v := range []uint8{r, g, b} {
+		if v >= hi/2+hi/4 {
+			idx |= 1 << i
+		}
+	}
+	attr := int(C.g_color_pair(C.int(9 + idx)))
+	switch {
+	case hi >= 0xC0:
+		attr |= AttrBold()
+	case hi < 0x70:
+		attr |= AttrDim()
+	}
+	return attr
 }
 
 func (c Color) Attr() int {
@@ -1781,6 +1815,8 @@ func KeyF0() int        { return KeyF(0) }
 func KeyResize() int    { return int(C.g_key_resize()) }
 func KeyUp() int        { return int(C.g_key_up()) }
 func KeyDown() int      { return int(C.g_key_down()) }
+func KeyLeft() int      { return int(C.g_key_left()) }
+func KeyRight() int     { return int(C.g_key_right()) }
 func KeyPageUp() int    { return int(C.g_key_ppage()) }
 func KeyPageDown() int  { return int(C.g_key_npage()) }
 func KeyHome() int      { return int(C.g_key_home()) }
@@ -0,0 +1,261 @@
+// Package atr reads ATR disk images: geometry, the boot record and Atari
+// DOS 2.x/MyDOS directories and files.
+package atr
+
+import (
+	"fmt"
+	"strings"
+)
+
+const headerSize = 16
+
+// Image is a parsed ATR disk image. Sectors are numbered from 1; the first
+// three are 128 bytes long on every density.
+type Image struct {
+	SectorSize int
+	Sectors    int
+	data       []byte
+	// padded is set for double density images that store the first three
+	// sectors in full 256-byte slots.
+	padded bool
+}
+
+// Parse reads the 16-byte header and locates the sectors that follow.
+func Parse(data []byte) (*Image, error) {
+	if len(data) < headerSize || data[0] != 0x96 || data[1] != 0x02 {
+		return nil, fmt.Errorf("Not an ATR image: missing $0296 signature.")
+	}
+	size := (int(data[2]) | int(data[3])<<8 | int(data[6])<<16) * 16
+	secSize := int(data[4]) | int(data[5])<<8
+	if secSize != 128 && secSize != 256 && secSize != 512 {
+		return nil, fmt.Errorf("Unsupported sector size: %d.", secSize)
+	}
+	body := data[headerSize:]
+	if size > len(body) {
+		return nil, fmt.Errorf("Image is truncated: header says %d bytes, file has %d.", size, len(body))
+	}
+	im := &Image{SectorSize: secSize, data: body[:size]}
+	switch {
+	case secSize == 128:
+		im.Sectors = size / 128
+	case size%secSize == 0:
+		im.padded = true
+		im.Sectors = size / secSize
+	case size > 3*128:
+		im.Sectors = 3 + (size-3*128)/secSize
+	}
+	// The sectors have to fill the image exactly.
+	if off, length := im.sectorSpan(im.Sectors); im.Sectors < 1 || off+length != size {
+		return nil, fmt.Errorf("Image size %d does not match %d sectors of %d bytes.", size, im.Sectors, secSize)
+	}
+	return im, nil
+}
+
+// Sector returns the data of sector n.
+func (im *Image) Sector(n int) ([]byte, error) {
+	if n < 1 || n > im.Sectors {
+		return nil, fmt.Errorf("Sector %d is outside the image (1-%d).", n, im.Sectors)
+	}
+	off, length := im.sectorSpan(n)
+	if off+length > len(im.data) {
+		return nil, fmt.Errorf("Sector %d runs past the end of the image.", n)
+	}
+	return im.data[off : off+length], nil
+}
+
+// sectorSpan returns the offset and length of sector n in the image data.
+func (im *Image) sectorSpan(n int) (int, int) {
+	off, length := 3*128+(n-4)*im.SectorSize, im.SectorSize
+	if n <= 3 {
+		off, length = (n-1)*128, 128
+	}
+	if im.padded {
+		off = (n - 1) * im.SectorSize
+	}
+	return off, length
+}
+
+// BootRecord is the header of the boot sectors. The OS loads Sectors
+// sectors at Load, jumps to Load+6 and, on success, through Init.
+type BootRecord struct {
+	Flags   byte
+	Sectors int
+	Load    uint16
+	Init    uint16
+}
+
+// Boot returns the boot record and the boot sectors as loaded.
+func (im *Image) Boot() (BootRecord, []byte, error) {
+	first, err := im.Sector(1)
+	if err != nil {
+		return BootRecord{}, nil, err
+	}
+	rec := BootRecord{
+		Flags:   first[0],
+		Sectors: int(first[1]),
+		Load:    uint16(first[2]) | uint16(first[3])<<8,
+		Init:    uint16(first[4]) | uint16(first[5])<<8,
+	}
+	var code []byte
+	for n := 1; n <= rec.Sectors; n++ {
+		sec, err := im.Sector(n)
+		if err != nil {
+			return rec, nil, err
+		}
+		code = append(code, sec...)
+	}
+	return rec, code, nil
+}
+
+const (
+	vtocSector = 360
+	dirSector  = 361
+	dirSectors = 8
+	dirEntry   = 16
+)
+
+// VTOC summarizes the volume table of contents in sector 360.
+type VTOC struct {
+	Code  byte
+	Total int
+	Free  int
+}
+
+// VTOC reads the DOS 2.x/MyDOS allocation summary.
+func (im *Image) VTOC() (VTOC, error) {
+	sec, err := im.Sector(vtocSector)
+	if err != nil {
+		return VTOC{}, err
+	}
+	return VTOC{
+		Code:  sec[0],
+		Total: int(sec[1]) | int(sec[2])<<8,
+		Free:  int(sec[3]) | int(sec[4])<<8,
+	}, nil
+}
+
+// Directory entry flags.
+const (
+	FlagOpen     = 0x01
+	FlagDOS2     = 0x02
+	FlagNoFileNo = 0x04
+	FlagDir      = 0x10
+	FlagLocked   = 0x20
+	FlagInUse    = 0x40
+	FlagDeleted  = 0x80
+)
+
+// File is a directory entry. Path joins MyDOS subdirectory names with '>'.
+type File struct {
+	Path    string
+	Flags   byte
+	Sectors int
+	Start   int
+	// number is the entry index DOS 2 stores in every data sector.
+	number int
+}
+
+func (f File) IsDir() bool {
+	return f.Flags&FlagDir != 0
+}
+
+// Files lists the directory at sector 361, descending into MyDOS
+// subdirectories. Deleted entries are skipped.
+func (im *Image) Files() ([]File, error) {
+	return im.readDir(dirSector, "", 0)
+}
+
+func (im *Image) readDir(start int, prefix string, depth int) ([]File, error) {
+	if depth > 8 {
+		return nil, fmt.Errorf("Directory nesting at sector %d is too deep.", start)
+	}
+	var out []File
+	for i := 0; i < dirSectors; i++ {
+		sec, err := im.Sector(start + i)
+		if err != nil {
+			return nil, err
+		}
+		for j := 0; j+dirEntry <= 128; j += dirEntry {
+			e := sec[j : j+dirEntry]
+			if e[0] == 0 {
+				return out, nil
+			}
+			if e[0]&FlagDeleted != 0 || e[0]&(FlagInUse|FlagDir) == 0 {
+				continue
+			}
+			name := fileName(e[5:13])
+			if ext := fileName(e[13:16]); ext != "" {
+				name += "." + ext
+			}
+			f := File{
+				Path:    prefix + name,
+				Flags:   e[0],
+				Sectors: int(e[1]) | int(e[2])<<8,
+				Start:   int(e[3]) | int(e[4])<<8,
+				number:  i*8 + j/dirEntry,
+			}
+			out = append(out, f)
+			if f.IsDir() {
+				sub, err := im.readDir(f.Start, f.Path+">", depth+1)
+				if err != nil {
+					return nil, err
+				}
+				out = append(out, sub...)
+			}
+		}
+	}
+	return out, nil
+}
+
+// fileName trims the padding from a directory name field. Atari names are
+// letters and digits, so path separators, dots and control characters
+// from a damaged or crafted directory are replaced with '_' to keep the
+// name a single path component.
+func fileName(field []byte) string {
+	name := []byte(strings.TrimRight(string(field), " "))
+	for i, c := range name {
+		if c < 0x20 || c >= 0x7F || strings.IndexByte("/\\.>:", c) >= 0 {
+			name[i] = '_'
+		}
+	}
+	return string(name)
+}
+
+// ReadFile follows the sector chain of f. Each data sector ends with the
+// file number and next sector link, then the count of used bytes. Files
+// flagged FlagNoFileNo use the full 16 bits for the link.
+func (im *Image) ReadFile(f File) ([]byte, error) {
+	if f.IsDir() {
+		return nil, fmt.Errorf("%s is a directory.", f.Path)
+	}
+	var out []byte
+	next := f.Start
+	for count := 0; next != 0; count++ {
+		if count > im.Sectors {
+			return nil, fmt.Errorf("%s: sector chain loops.", f.Path)
+		}
+		sec, err := im.Sector(next)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", f.Path, err)
+		}
+		n := len(sec)
+		link := sec[n-3 : n]
+		used := int(link[2])
+		if n == 128 {
+			used &= 0x7F
+		}
+		if used > n-3 {
+			return nil, fmt.Errorf("%s: sector %d claims %d bytes.", f.Path, next, used)
+		}
+		out = append(out, sec[:used]...)
+		if f.Flags&FlagNoFileNo != 0 {
+			next = int(link[0])<<8 | int(link[1])
+			continue
+		}
+		if number := int(link[0] >> 2); number != f.number {
+			return nil, fmt.Errorf("%s: sector %d belongs to file %d, not %d.", f.Path, next, number, f.number)
+		}
+		next = int(link[0]&0x03)<<8 | int(link[1])
+	}
+	return out, nil
+}
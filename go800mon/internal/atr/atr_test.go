@@ -0,0 +1,108 @@
+package atr
+
+import (
+	"bytes"
+	"testing"
+)
+
+// image builds an ATR with sectors of secSize bytes, the first three short.
+func image(secSize, sectors int) []byte {
+	size := 3*128 + (sectors-3)*secSize
+	data := make([]byte, headerSize+size)
+	paras := size / 16
+	data[0], data[1] = 0x96, 0x02
+	data[2], data[3], data[6] = byte(paras), byte(paras>>8), byte(paras>>16)
+	data[4], data[5] = byte(secSize), byte(secSize>>8)
+	return data
+}
+
+// sector returns sector n of a single density image built by image.
+func sector(data []byte, n int) []byte {
+	off := headerSize + (n-1)*128
+	return data[off : off+128]
+}
+
+func dirEntryAt(data []byte, index int, flags byte, start int, name string) {
+	e := sector(data, dirSector)[index*dirEntry : (index+1)*dirEntry]
+	e[0], e[1], e[3], e[4] = flags, 1, byte(start), byte(start>>8)
+	copy(e[5:16], []byte(name))
+}
+
+func TestParseGeometry(t *testing.T) {
+	for _, tt := range []struct {
+		secSize, sectors int
+	}{{128, 720}, {256, 720}, {128, 1040}} {
+		im, err := Parse(image(tt.secSize, tt.sectors))
+		if err != nil {
+			t.Fatalf("%d x %d: %v", tt.sectors, tt.secSize, err)
+		}
+		if im.Sectors != tt.sectors || im.SectorSize != tt.secSize {
+			t.Errorf("Parse = %d x %d, want %d x %d", im.Sectors, im.SectorSize, tt.sectors, tt.secSize)
+		}
+		if sec, err := im.Sector(4); err != nil || len(sec) != tt.secSize {
+			t.Errorf("Sector(4) = %d bytes, %v", len(sec), err)
+		}
+		for _, n := range []int{0, tt.sectors + 1} {
+			if _, err := im.Sector(n); err == nil {
+				t.Errorf("Sector(%d) succeeded", n)
+			}
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	bad := image(128, 720)
+	bad[0] = 0
+	mismatch := image(256, 720)
+	mismatch[2]++ // 16 bytes more than whole sectors hold
+	mismatch = append(mismatch, make([]byte, 16)...)
+	short := image(256, 4)
+	short[2], short[3] = 8, 0 // 128 bytes: fewer than the three boot sectors
+	for name, data := range map[string][]byte{
+		"signature":   bad,
+		"truncated":   image(128, 720)[:1000],
+		"sector size": append([]byte{0x96, 0x02, 0x08, 0x00, 0x40, 0x00}, make([]byte, 200)...),
+		"mismatch":    mismatch,
+		"too short":   short,
+	} {
+		if _, err := Parse(data); err == nil {
+			t.Errorf("%s: Parse succeeded, want error", name)
+		}
+	}
+}
+
+func TestFilesAndReadFile(t *testing.T) {
+	data := image(128, 720)
+	dirEntryAt(data, 0, FlagInUse|FlagDOS2, 400, "HELLO   TXT")
+	dirEntryAt(data, 1, FlagInUse|FlagDOS2, 401, "../ETC\x1b    ")
+	dirEntryAt(data, 2, FlagDeleted, 402, "GONE    ")
+	sec := sector(data, 400)
+	copy(sec, "HI")
+	sec[127] = 2
+	sec = sector(data, 401)
+	sec[125] = 1 << 2 // file number 1
+	im, err := Parse(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	files, err := im.Files()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 2 || files[0].Path != "HELLO.TXT" || files[1].Path != "___ETC_" {
+		t.Fatalf("Files = %+v", files)
+	}
+	got, err := im.ReadFile(files[0])
+	if err != nil || !bytes.Equal(got, []byte("HI")) {
+		t.Fatalf("ReadFile = %q, %v", got, err)
+	}
+	if got, err := im.ReadFile(files[1]); err != nil || len(got) != 0 {
+		t.Fatalf("ReadFile = %q, %v", got, err)
+	}
+	// A link past the end of the image is an error, not a panic.
+	sector(data, 400)[126] = 0xFF
+	sector(data, 400)[125] = 0x03
+	if _, err := im.ReadFile(files[0]); err == nil {
+		t.Fatal("ReadFile followed a link outside the image")
+	}
+}
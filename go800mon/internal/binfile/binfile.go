@@ -0,0 +1,225 @@
+// Package binfile reads and writes memory images as Atari XEX executables
+// and Intel HEX files.
+package binfile
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// RUNAD and INITAD are the DOS vectors an XEX file loads to request a jump
+// after loading, or a call right after the segment that sets it.
+const (
+	RUNAD  uint16 = 0x02E0
+	INITAD uint16 = 0x02E2
+)
+
+// Segment is a block of data loaded at Addr.
+type Segment struct {
+	Addr uint16
+	Data []byte
+}
+
+// End is the address of the last byte of s.
+func (s Segment) End() uint16 {
+	return s.Addr + uint16(len(s.Data)) - 1
+}
+
+// Word returns the little-endian word s loads at addr, if it covers both
+// bytes.
+func (s Segment) Word(addr uint16) (uint16, bool) {
+	off := int(addr) - int(s.Addr)
+	if off < 0 || off+1 >= len(s.Data) {
+		return 0, false
+	}
+	return uint16(s.Data[off]) | uint16(s.Data[off+1])<<8, true
+}
+
+// IsXEX reports whether data starts with the $FFFF executable header.
+func IsXEX(data []byte) bool {
+	return len(data) >= 2 && data[0] == 0xFF && data[1] == 0xFF
+}
+
+// ParseXEX splits an Atari executable into its segments in load order. The
+// $FFFF header is required at the start and optional before later segments.
+func ParseXEX(data []byte) ([]Segment, error) {
+	if !IsXEX(data) {
+		return nil, fmt.Errorf("Not an XEX file: missing $FFFF header.")
+	}
+	var out []Segment
+	pos := 0
+	for pos < len(data) {
+		if pos+1 < len(data) && data[pos] == 0xFF && data[pos+1] == 0xFF {
+			pos += 2
+		}
+		if pos+4 > len(data) {
+			return nil, fmt.Errorf("Truncated segment header at offset %d.", pos)
+		}
+		start := uint16(data[pos]) | uint16(data[pos+1])<<8
+		end := uint16(data[pos+2]) | uint16(data[pos+3])<<8
+		pos += 4
+		if end < start {
+			return nil, fmt.Errorf("Segment %04X-%04X ends before it starts.", start, end)
+		}
+		n := int(end-start) + 1
+		if pos+n > len(data) {
+			return nil, fmt.Errorf("Segment %04X-%04X is truncated: %d of %d bytes.", start, end, len(data)-pos, n)
+		}
+		out = append(out, Segment{Addr: start, Data: data[pos : pos+n]})
+		pos += n
+	}
+	return out, nil
+}
+
+// EncodeXEX writes segs as an Atari executable with a single $FFFF header.
+func EncodeXEX(segs []Segment) []byte {
+	out := []byte{0xFF, 0xFF}
+	for _, s := range segs {
+		end := s.End()
+		out = append(out, byte(s.Addr), byte(s.Addr>>8), byte(end), byte(end>>8))
+		out = append(out, s.Data...)
+	}
+	return out
+}
+
+// ParseIHex reads Intel HEX data records into segments, merging records
+// that continue the previous one. Only the 16-bit address space is
+// accepted.
+func ParseIHex(data []byte) ([]Segment, error) {
+	var out []Segment
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	for lineNo := 1; sc.Scan(); lineNo++ {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" {
+			continue
+		}
+		if line[0] != ':' {
+			return nil, fmt.Errorf("Line %d: record does not start with ':'.", lineNo)
+		}
+		rec, err := hex.DecodeString(line[1:])
+		if err != nil || len(rec) < 5 || len(rec) != int(rec[0])+5 {
+			return nil, fmt.Errorf("Line %d: malformed record.", lineNo)
+		}
+		var sum byte
+		for _, b := range rec {
+			sum += b
+		}
+		if sum != 0 {
+			return nil, fmt.Errorf("Line %d: checksum mismatch.", lineNo)
+		}
+		addr := uint16(rec[1])<<8 | uint16(rec[2])
+		payload := rec[4 : len(rec)-1]
+		switch rec[3] {
+		case 0x00:
+			if len(payload) == 0 {
+				continue
+			}
+			if n := len(out); n > 0 && out[n-1].End()+1 == addr && int(out[n-1].Addr)+len(out[n-1].Data) < 0x10000 {
+				out[n-1].Data = append(out[n-1].Data, payload...)
+			} else {
+				out = append(out, Segment{Addr: addr, Data: append([]byte(nil), payload...)})
+			}
+		case 0x01:
+			return out, nil
+		case 0x02, 0x04:
+			if len(bytes.TrimLeft(payload, "\x00")) != 0 {
+				return nil, fmt.Errorf("Line %d: extended addresses above $FFFF are not supported.", lineNo)
+			}
+		case 0x03, 0x05:
+		default:
+			return nil, fmt.Errorf("Line %d: unknown record type %02X.", lineNo, rec[3])
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// EncodeIHex writes segs as Intel HEX with 16-byte data records.
+func EncodeIHex(segs []Segment) []byte {
+	var b strings.Builder
+	record := func(addr uint16, typ byte, payload []byte) {
+		rec := append([]byte{byte(len(payload)), byte(addr >> 8), byte(addr), typ}, payload...)
+		var sum byte
+		for _, v := range rec {
+			sum += v
+		}
+		rec = append(rec, -sum)
+		b.WriteString(":" + strings.ToUpper(hex.EncodeToString(rec)) + "\n")
+	}
+	for _, s := range segs {
+		for off := 0; off < len(s.Data); off += 16 {
+			record(s.Addr+uint16(off), 0x00, s.Data[off:min(off+16, len(s.Data))])
+		}
+	}
+	record(0, 0x01, nil)
+	return []byte(b.String())
+}
+
+// Entry is a jump the DOS loader makes: INIT right after the segment that
+// loads INITAD, RUN to the last RUNAD once the whole file is loaded. Segment
+// is the index of the segment loaded last before the jump.
+type Entry struct {
+	Kind    string
+	Addr    uint16
+	Segment int
+}
+
+func Entries(segs []Segment) []Entry {
+	var out []Entry
+	var run *Entry
+	for i, s := range segs {
+		if addr, ok := s.Word(INITAD); ok {
+			out = append(out, Entry{Kind: "INIT", Addr: addr, Segment: i})
+		}
+		if addr, ok := s.Word(RUNAD); ok {
+			run = &Entry{Kind: "RUN", Addr: addr, Segment: len(segs) - 1}
+		}
+	}
+	if run != nil {
+		out = append(out, *run)
+	}
+	return out
+}
+
+// Overwrite is the part Start-End of segment Segment that the later segment
+// By loads over.
+type Overwrite struct {
+	Segment int
+	By      int
+	Start   uint16
+	End     uint16
+}
+
+// Overwrites finds data replaced by later segments. Reloading the RUNAD and
+// INITAD vectors is how DOS files work and is not reported.
+func Overwrites(segs []Segment) []Overwrite {
+	var out []Overwrite
+	for i, a := range segs {
+		for j := i + 1; j < len(segs); j++ {
+			b := segs[j]
+			start, end := max(a.Addr, b.Addr), min(a.End(), b.End())
+			if start > end || (start >= RUNAD && end <= INITAD+1) {
+				continue
+			}
+			out = append(out, Overwrite{Segment: i, By: j, Start: start, End: end})
+		}
+	}
+	return out
+}
+
+// Image is memory as the loader leaves it after segs, with loaded marking
+// the bytes they cover.
+func Image(segs []Segment) (mem *[0x10000]byte, loaded *[0x10000]bool) {
+	mem, loaded = new([0x10000]byte), new([0x10000]bool)
+	for _, s := range segs {
+		for i, b := range s.Data {
+			mem[s.Addr+uint16(i)], loaded[s.Addr+uint16(i)] = b, true
+		}
+	}
+	return mem, loaded
+}
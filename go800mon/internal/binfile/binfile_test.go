@@ -0,0 +1,88 @@
+package binfile
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestParseXEX(t *testing.T) {
+	data := []byte{
+		0xFF, 0xFF, 0x00, 0x20, 0x02, 0x20, 0xA9, 0x01, 0x60,
+		0xFF, 0xFF, 0xE2, 0x02, 0xE3, 0x02, 0x00, 0x20,
+		0xE0, 0x02, 0xE1, 0x02, 0x01, 0x20,
+	}
+	segs, err := ParseXEX(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Segment{
+		{Addr: 0x2000, Data: []byte{0xA9, 0x01, 0x60}},
+		{Addr: INITAD, Data: []byte{0x00, 0x20}},
+		{Addr: RUNAD, Data: []byte{0x01, 0x20}},
+	}
+	if len(segs) != len(want) {
+		t.Fatalf("got %d segments, want %d", len(segs), len(want))
+	}
+	for i := range want {
+		if segs[i].Addr != want[i].Addr || !bytes.Equal(segs[i].Data, want[i].Data) {
+			t.Errorf("segment %d = $%04X % X, want $%04X % X", i, segs[i].Addr, segs[i].Data, want[i].Addr, want[i].Data)
+		}
+	}
+	// The optional $FFFF before the second segment is not written back.
+	encoded := append(append([]byte(nil), data[:9]...), data[11:]...)
+	if got := EncodeXEX(segs); !bytes.Equal(got, encoded) {
+		t.Errorf("EncodeXEX = % X, want % X", got, encoded)
+	}
+	entries := Entries(segs)
+	if len(entries) != 2 || entries[0] != (Entry{Kind: "INIT", Addr: 0x2000, Segment: 1}) || entries[1] != (Entry{Kind: "RUN", Addr: 0x2001, Segment: 2}) {
+		t.Errorf("Entries = %+v", entries)
+	}
+}
+
+func TestParseXEXErrors(t *testing.T) {
+	for name, data := range map[string][]byte{
+		"no header":        {0x00, 0x20, 0x00, 0x20, 0x60},
+		"truncated header": {0xFF, 0xFF, 0x00, 0x20, 0x00},
+		"reversed range":   {0xFF, 0xFF, 0x01, 0x20, 0x00, 0x20, 0x60, 0x60},
+		"truncated data":   {0xFF, 0xFF, 0x00, 0x20, 0x03, 0x20, 0x60},
+	} {
+		if _, err := ParseXEX(data); err == nil {
+			t.Errorf("%s: ParseXEX succeeded, want error", name)
+		}
+	}
+}
+
+func TestOverwrites(t *testing.T) {
+	segs := []Segment{
+		{Addr: 0x2000, Data: make([]byte, 16)},
+		{Addr: RUNAD, Data: []byte{0x00, 0x20}},
+		{Addr: 0x2008, Data: make([]byte, 16)},
+		{Addr: RUNAD, Data: []byte{0x08, 0x20}},
+	}
+	got := Overwrites(segs)
+	if len(got) != 1 || got[0] != (Overwrite{Segment: 0, By: 2, Start: 0x2008, End: 0x200F}) {
+		t.Fatalf("Overwrites = %+v", got)
+	}
+}
+
+func TestIHexRoundTrip(t *testing.T) {
+	segs := []Segment{
+		{Addr: 0x0600, Data: bytes.Repeat([]byte{0xEA}, 20)},
+		{Addr: 0xFFF0, Data: bytes.Repeat([]byte{0x55}, 16)},
+	}
+	got, err := ParseIHex(EncodeIHex(segs))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != len(segs) {
+		t.Fatalf("got %d segments, want %d", len(got), len(segs))
+	}
+	for i := range segs {
+		if got[i].Addr != segs[i].Addr || !bytes.Equal(got[i].Data, segs[i].Data) {
+			t.Errorf("segment %d = $%04X % X", i, got[i].Addr, got[i].Data)
+		}
+	}
+	if _, err := ParseIHex([]byte(":0100000001FF\n")); err == nil {
+		t.Error("ParseIHex accepted a bad checksum")
+	}
+}
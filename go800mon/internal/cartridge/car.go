@@ -0,0 +1,51 @@
+package cartridge
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+const carHeaderSize = 16
+
+// CAR is a parsed CAR image: a 16-byte header with the "CART" magic, the
+// type id and the checksum, both big-endian, followed by the ROM data.
+type CAR struct {
+	Type     int
+	Checksum uint32
+	Data     []byte
+}
+
+func ParseCAR(data []byte) (CAR, error) {
+	if len(data) < carHeaderSize || string(data[:4]) != "CART" {
+		return CAR{}, fmt.Errorf("Not a CAR image: missing CART header.")
+	}
+	return CAR{
+		Type:     int(binary.BigEndian.Uint32(data[4:8])),
+		Checksum: binary.BigEndian.Uint32(data[8:12]),
+		Data:     data[carHeaderSize:],
+	}, nil
+}
+
+// Sum is the checksum CAR headers store: the sum of all ROM bytes.
+func Sum(data []byte) uint32 {
+	var sum uint32
+	for _, b := range data {
+		sum += uint32(b)
+	}
+	return sum
+}
+
+// Problems lists what does not match between the header and the data.
+func (c CAR) Problems() []string {
+	var out []string
+	if sum := Sum(c.Data); sum != c.Checksum {
+		out = append(out, fmt.Sprintf("Checksum mismatch: header %08X, data %08X.", c.Checksum, sum))
+	}
+	t, ok := Lookup(c.Type)
+	if !ok {
+		out = append(out, fmt.Sprintf("Unknown cartridge type %d.", c.Type))
+	} else if len(c.Data) != t.SizeKB*1024 {
+		out = append(out, fmt.Sprintf("Data is %d bytes, %s needs %d.", len(c.Data), t.Name, t.SizeKB*1024))
+	}
+	return out
+}
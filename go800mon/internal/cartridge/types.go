@@ -0,0 +1,208 @@
+// Package cartridge describes the cartridge types Atari800 emulates and
+// reads CAR image headers.
+package cartridge
+
+import "fmt"
+
+// Scheme is the way a cartridge type switches banks into the $A000-$BFFF
+// (or $8000-$BFFF) window.
+type Scheme int
+
+const (
+	SchemeNone Scheme = iota
+	SchemeXEGS
+	SchemeSwitchableXEGS
+	SchemeMegaCart
+	SchemeWilliams
+	SchemeExpress
+	SchemeAtarimax
+	SchemeOneShot
+	SchemeOSS
+	SchemeSuperCart
+	SchemeOther
+)
+
+var schemeNames = map[Scheme]string{
+	SchemeNone:           "none",
+	SchemeXEGS:           "XEGS $D5xx data",
+	SchemeSwitchableXEGS: "XEGS $D5xx data, bit 7 disables",
+	SchemeMegaCart:       "MegaCart $D5xx data, bit 7 disables",
+	SchemeWilliams:       "Williams $D50x address, bit 3 disables",
+	SchemeExpress:        "Express/Diamond/SDX $D5x0-$D5x7 address, inverted, bit 3 disables",
+	SchemeAtarimax:       "Atarimax $D5xx address, top bit disables",
+	SchemeOneShot:        "any $D5xx access disables",
+	SchemeOSS:            "OSS $D50x address",
+	SchemeSuperCart:      "5200 Super Cart $BFC0-$BFFF access",
+	SchemeOther:          "other",
+}
+
+func (s Scheme) String() string {
+	if name, ok := schemeNames[s]; ok {
+		return name
+	}
+	return "unknown"
+}
+
+// Type is an Atari800 CART_* cartridge type.
+type Type struct {
+	ID     int
+	Name   string
+	SizeKB int
+	Scheme Scheme
+	// BankKB is the size of one switchable bank, 0 when there is only one.
+	BankKB int
+}
+
+// Types is indexed by the ids CAR headers and CART_STATE use, up to
+// CART_ATMAX_NEW_1024, the last type Atari800 emulates.
+var Types = []Type{
+	{1, "Standard 8 KB", 8, SchemeNone, 0},
+	{2, "Standard 16 KB", 16, SchemeNone, 0},
+	{3, "OSS two chip 16 KB (034M)", 16, SchemeOSS, 4},
+	{4, "Atari 5200 32 KB", 32, SchemeNone, 0},
+	{5, "DB 32 KB", 32, SchemeOther, 8},
+	{6, "Atari 5200 two chip 16 KB", 16, SchemeNone, 0},
+	{7, "Atari 5200 Bounty Bob 40 KB", 40, SchemeOther, 4},
+	{8, "Williams 64 KB", 64, SchemeWilliams, 8},
+	{9, "Express 64 KB", 64, SchemeExpress, 8},
+	{10, "Diamond 64 KB", 64, SchemeExpress, 8},
+	{11, "SpartaDOS X 64 KB", 64, SchemeExpress, 8},
+	{12, "XEGS 32 KB", 32, SchemeXEGS, 8},
+	{13, "XEGS 64 KB (banks 0-7)", 64, SchemeXEGS, 8},
+	{14, "XEGS 128 KB", 128, SchemeXEGS, 8},
+	{15, "OSS one chip 16 KB", 16, SchemeOSS, 4},
+	{16, "Atari 5200 one chip 16 KB", 16, SchemeNone, 0},
+	{17, "Decoded Atrax 128 KB", 128, SchemeOther, 8},
+	{18, "Bounty Bob 40 KB", 40, SchemeOther, 4},
+	{19, "Atari 5200 8 KB", 8, SchemeNone, 0},
+	{20, "Atari 5200 4 KB", 4, SchemeNone, 0},
+	{21, "Right slot 8 KB", 8, SchemeNone, 0},
+	{22, "Williams 32 KB", 32, SchemeWilliams, 8},
+	{23, "XEGS 256 KB", 256, SchemeXEGS, 8},
+	{24, "XEGS 512 KB", 512, SchemeXEGS, 8},
+	{25, "XEGS 1 MB", 1024, SchemeXEGS, 8},
+	{26, "MegaCart 16 KB", 16, SchemeMegaCart, 16},
+	{27, "MegaCart 32 KB", 32, SchemeMegaCart, 16},
+	{28, "MegaCart 64 KB", 64, SchemeMegaCart, 16},
+	{29, "MegaCart 128 KB", 128, SchemeMegaCart, 16},
+	{30, "MegaCart 256 KB", 256, SchemeMegaCart, 16},
+	{31, "MegaCart 512 KB", 512, SchemeMegaCart, 16},
+	{32, "MegaCart 1 MB", 1024, SchemeMegaCart, 16},
+	{33, "Switchable XEGS 32 KB", 32, SchemeSwitchableXEGS, 8},
+	{34, "Switchable XEGS 64 KB", 64, SchemeSwitchableXEGS, 8},
+	{35, "Switchable XEGS 128 KB", 128, SchemeSwitchableXEGS, 8},
+	{36, "Switchable XEGS 256 KB", 256, SchemeSwitchableXEGS, 8},
+	{37, "Switchable XEGS 512 KB", 512, SchemeSwitchableXEGS, 8},
+	{38, "Switchable XEGS 1 MB", 1024, SchemeSwitchableXEGS, 8},
+	{39, "Phoenix 8 KB", 8, SchemeOneShot, 0},
+	{40, "Blizzard 16 KB", 16, SchemeOneShot, 0},
+	{41, "Atarimax 128 KB Flash", 128, SchemeAtarimax, 8},
+	{42, "Atarimax 1 MB Flash (old)", 1024, SchemeAtarimax, 8},
+	{43, "SpartaDOS X 128 KB", 128, SchemeOther, 8},
+	{44, "OSS 8 KB", 8, SchemeOSS, 4},
+	{45, "OSS two chip 16 KB (043M)", 16, SchemeOSS, 4},
+	{46, "Blizzard 4 KB", 4, SchemeOneShot, 0},
+	{47, "AST 32 KB", 32, SchemeOther, 0},
+	{48, "Atrax SDX 64 KB", 64, SchemeOther, 8},
+	{49, "Atrax SDX 128 KB", 128, SchemeOther, 8},
+	{50, "Turbosoft 64 KB", 64, SchemeOther, 8},
+	{51, "Turbosoft 128 KB", 128, SchemeOther, 8},
+	{52, "Ultracart 32 KB", 32, SchemeOther, 8},
+	{53, "Low bank 8 KB", 8, SchemeNone, 0},
+	{54, "SIC! 128 KB", 128, SchemeOther, 16},
+	{55, "SIC! 256 KB", 256, SchemeOther, 16},
+	{56, "SIC! 512 KB", 512, SchemeOther, 16},
+	{57, "Standard 2 KB", 2, SchemeNone, 0},
+	{58, "Standard 4 KB", 4, SchemeNone, 0},
+	{59, "Right slot 4 KB", 4, SchemeNone, 0},
+	{60, "Blizzard 32 KB", 32, SchemeOther, 8},
+	{61, "MegaMax 2 MB", 2048, SchemeOther, 16},
+	{62, "The!Cart 128 MB", 128 * 1024, SchemeOther, 8},
+	{63, "Flash MegaCart 4 MB", 4096, SchemeOther, 16},
+	{64, "MegaCart 2 MB", 2048, SchemeOther, 16},
+	{65, "The!Cart 32 MB", 32 * 1024, SchemeOther, 8},
+	{66, "The!Cart 64 MB", 64 * 1024, SchemeOther, 8},
+	{67, "XEGS 64 KB (banks 8-15)", 64, SchemeXEGS, 8},
+	{68, "Atrax 128 KB", 128, SchemeOther, 8},
+	{69, "aDawliah 32 KB", 32, SchemeOther, 8},
+	{70, "aDawliah 64 KB", 64, SchemeOther, 8},
+	{71, "Atari 5200 Super Cart 64 KB", 64, SchemeSuperCart, 32},
+	{72, "Atari 5200 Super Cart 128 KB", 128, SchemeSuperCart, 32},
+	{73, "Atari 5200 Super Cart 256 KB", 256, SchemeSuperCart, 32},
+	{74, "Atari 5200 Super Cart 512 KB", 512, SchemeSuperCart, 32},
+	{75, "Atarimax 1 MB Flash (new)", 1024, SchemeAtarimax, 8},
+}
+
+// Lookup finds the type with the given id.
+func Lookup(id int) (Type, bool) {
+	if id >= 1 && id <= len(Types) && Types[id-1].ID == id {
+		return Types[id-1], true
+	}
+	return Type{}, false
+}
+
+// Name returns the type name for id, or the bare number for ids missing
+// from the table.
+func Name(id int) string {
+	switch id {
+	case 0:
+		return "none"
+	case -1:
+		return "unknown"
+	}
+	if t, ok := Lookup(id); ok {
+		return t.Name
+	}
+	return fmt.Sprintf("type %d", id)
+}
+
+// Banks is the number of switchable banks, 1 for unbanked types.
+func (t Type) Banks() int {
+	if t.BankKB == 0 {
+		return 1
+	}
+	return max(1, t.SizeKB/t.BankKB)
+}
+
+// Bank is the bank selected by a cartridge state.
+type Bank struct {
+	Index    int
+	Disabled bool
+}
+
+// Bank decodes state, the bank register value Atari800 keeps for the slot.
+// It reports false for schemes whose state is not a plain bank number.
+func (t Type) Bank(state uint32) (Bank, bool) {
+	mask := uint32(t.Banks() - 1)
+	switch t.Scheme {
+	case SchemeNone:
+		return Bank{}, true
+	case SchemeXEGS, SchemeSuperCart:
+		return Bank{Index: int(state & mask)}, true
+	case SchemeSwitchableXEGS, SchemeMegaCart:
+		return Bank{Index: int(state & mask), Disabled: state&0x80 != 0}, true
+	case SchemeWilliams:
+		return Bank{Index: int(state & 0x07 & mask), Disabled: state&0x08 != 0}, true
+	case SchemeExpress:
+		return Bank{Index: int(^state & 0x07), Disabled: state&0x08 != 0}, true
+	case SchemeAtarimax:
+		return Bank{Index: int(state & mask), Disabled: state&(mask+1) != 0}, true
+	case SchemeOneShot:
+		return Bank{Disabled: state != 0}, true
+	}
+	return Bank{}, false
+}
+
+// DescribeBank formats the bank selected by state for status displays.
+func (t Type) DescribeBank(state uint32) string {
+	bank, ok := t.Bank(state)
+	switch {
+	case !ok:
+		return fmt.Sprintf("state %02X", state)
+	case bank.Disabled:
+		return "disabled"
+	case t.Banks() == 1:
+		return ""
+	}
+	return fmt.Sprintf("bank %d/%d", bank.Index, t.Banks())
+}
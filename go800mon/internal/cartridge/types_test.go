@@ -0,0 +1,37 @@
+package cartridge
+
+import "testing"
+
+func TestTypesIndexedByID(t *testing.T) {
+	for i, typ := range Types {
+		if typ.ID != i+1 {
+			t.Fatalf("Types[%d] has id %d", i, typ.ID)
+		}
+	}
+	if _, ok := Lookup(75); !ok {
+		t.Fatal("Lookup(75) failed")
+	}
+}
+
+func TestDescribeBank(t *testing.T) {
+	tests := []struct {
+		id    int
+		state uint32
+		want  string
+	}{
+		{1, 0, ""},
+		{12, 0x03, "bank 3/4"},
+		{33, 0x82, "disabled"},
+		{9, 0x05, "bank 2/8"},
+		{75, 0x7F, "bank 127/128"},
+		{75, 0x80, "disabled"},
+		{73, 0x05, "bank 5/8"},
+		{47, 0x01, "state 01"},
+	}
+	for _, tt := range tests {
+		typ, _ := Lookup(tt.id)
+		if got := typ.DescribeBank(tt.state); got != tt.want {
+			t.Errorf("%s DescribeBank(%02X) = %q, want %q", typ.Name, tt.state, got, tt.want)
+		}
+	}
+}
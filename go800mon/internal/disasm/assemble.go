@@ -7,9 +7,14 @@ import (
 	"unicode"
 )
 
-var asmOpcodesByMnemonic = buildAsmOpcodesByMnemonic()
+var (
+	asmOpcodesByMnemonic        = buildAsmOpcodesByMnemonic(false)
+	asmIllegalOpcodesByMnemonic = buildAsmOpcodesByMnemonic(true)
+)
 
-func AssembleOne(addr uint16, statement string) ([]byte, error) {
+// AssembleOne encodes a single statement at addr. Undocumented mnemonics are
+// accepted only when illegal is set.
+func AssembleOne(addr uint16, statement string, illegal bool) ([]byte, error) {
 	text := strings.TrimSpace(strings.SplitN(statement, ";", 2)[0])
 	if text == "" {
 		return nil, fmt.Errorf("empty instruction")
@@ -21,11 +26,15 @@ func AssembleOne(addr uint16, statement string) ([]byte, error) {
 	if isDataMnemonic(mnemonic) {
 		return assembleDataBytes(operand)
 	}
-	modes, ok := asmOpcodesByMnemonic[mnemonic]
+	table := asmOpcodesByMnemonic
+	if illegal {
+		table = asmIllegalOpcodesByMnemonic
+	}
+	modes, ok := table[mnemonic]
 	if !ok {
 		return nil, fmt.Errorf("unknown mnemonic: %s", mnemonic)
 	}
-	mode, value, err := parseAsmOperand(operand)
+	mode, value, err := parseAsmOperand(operand, parseAsmValue)
 	if err != nil {
 		return nil, err
 	}
@@ -36,11 +45,10 @@ func AssembleOne(addr uint16, statement string) ([]byte, error) {
 	return encodeAsmInstruction(opcode, resolvedMode, value, addr)
 }
 
-func buildAsmOpcodesByMnemonic() map[string]map[string]byte {
+func buildAsmOpcodesByMnemonic(illegal bool) map[string]map[string]byte {
 	out := make(map[string]map[string]byte, 64)
 	for opcode := 0; opcode < 256; opcode++ {
-		mn := opMnemonic[opcode]
-		mode := opMode[opcode]
+		mn, mode := opcodeInfo(byte(opcode), illegal)
 		if mn == "???" || mode == "" {
 			continue
 		}
@@ -49,7 +57,8 @@ func buildAsmOpcodesByMnemonic() map[string]map[string]byte {
 			row = make(map[string]byte, 8)
 			out[mn] = row
 		}
-		if _, exists := row[mode]; !exists {
+		// Documented encodings win over undocumented duplicates such as $EB SBC.
+		if _, exists := row[mode]; !exists || opMnemonic[opcode] != "???" {
 			row[mode] = byte(opcode)
 		}
 	}
@@ -93,16 +102,16 @@ func assembleDataBytes(operand string) ([]byte, error) {
 	return out, nil
 }
 
-func parseAsmOperand(operand string) (string, uint16, error) {
+func parseAsmOperand(operand string, parseValue func(string) (uint16, error)) (string, uint16, error) {
 	text := strings.TrimSpace(operand)
 	if text == "" {
 		return "imp", 0, nil
 	}
-	if text == "A" {
+	if strings.EqualFold(text, "A") {
 		return "acc", 0, nil
 	}
 	if strings.HasPrefix(text, "#") {
-		v, err := parseAsmValue(text[1:])
+		v, err := parseValue(text[1:])
 		if err != nil {
 			return "", 0, err
 		}
@@ -112,8 +121,8 @@ func parseAsmOperand(operand string) (string, uint16, error) {
 		return "imm", v, nil
 	}
 	if strings.HasPrefix(text, "(") {
-		if len(text) > 4 && strings.HasSuffix(text, ",X)") {
-			v, err := parseAsmValue(strings.TrimSpace(text[1 : len(text)-3]))
+		if len(text) > 4 && hasSuffixFold(text, ",X)") {
+			v, err := parseValue(strings.TrimSpace(text[1 : len(text)-3]))
 			if err != nil {
 				return "", 0, err
 			}
@@ -122,8 +131,8 @@ func parseAsmOperand(operand string) (string, uint16, error) {
 			}
 			return "inx", v, nil
 		}
-		if len(text) > 4 && strings.HasSuffix(text, "),Y") {
-			v, err := parseAsmValue(strings.TrimSpace(text[1 : len(text)-3]))
+		if len(text) > 4 && hasSuffixFold(text, "),Y") {
+			v, err := parseValue(strings.TrimSpace(text[1 : len(text)-3]))
 			if err != nil {
 				return "", 0, err
 			}
@@ -133,7 +142,7 @@ func parseAsmOperand(operand string) (string, uint16, error) {
 			return "iny", v, nil
 		}
 		if len(text) > 2 && strings.HasSuffix(text, ")") {
-			v, err := parseAsmValue(strings.TrimSpace(text[1 : len(text)-1]))
+			v, err := parseValue(strings.TrimSpace(text[1 : len(text)-1]))
 			if err != nil {
 				return "", 0, err
 			}
@@ -141,27 +150,33 @@ func parseAsmOperand(operand string) (string, uint16, error) {
 		}
 		return "", 0, fmt.Errorf("invalid operand syntax")
 	}
-	if strings.HasSuffix(text, ",X") {
-		v, err := parseAsmValue(strings.TrimSpace(text[:len(text)-2]))
+	if hasSuffixFold(text, ",X") {
+		v, err := parseValue(strings.TrimSpace(text[:len(text)-2]))
 		if err != nil {
 			return "", 0, err
 		}
 		return "memx", v, nil
 	}
-	if strings.HasSuffix(text, ",Y") {
-		v, err := parseAsmValue(strings.TrimSpace(text[:len(text)-2]))
+	if hasSuffixFold(text, ",Y") {
+		v, err := parseValue(strings.TrimSpace(text[:len(text)-2]))
 		if err != nil {
 			return "", 0, err
 		}
 		return "memy", v, nil
 	}
-	v, err := parseAsmValue(text)
+	v, err := parseValue(text)
 	if err != nil {
 		return "", 0, err
 	}
 	return "mem", v, nil
 }
 
+// hasSuffixFold reports whether text ends in suffix, ignoring case, so index
+// registers match in lower case without folding character literals.
+func hasSuffixFold(text, suffix string) bool {
+	return len(text) >= len(suffix) && strings.EqualFold(text[len(text)-len(suffix):], suffix)
+}
+
 func parseAsmValue(token string) (uint16, error) {
 	text := strings.TrimSpace(token)
 	if text == "" {
@@ -208,6 +223,11 @@ func resolveAsmOpcode(modes map[string]byte, mode string, value uint16) (string,
 	switch mode {
 	case "imp", "acc", "imm", "ind", "inx", "iny":
 		opcode, ok := modes[mode]
+		if !ok && mode == "imp" {
+			// A bare ASL/LSR/ROL/ROR means the accumulator.
+			mode = "acc"
+			opcode, ok = modes[mode]
+		}
 		if !ok {
 			return "", 0, fmt.Errorf("unsupported addressing mode")
 		}
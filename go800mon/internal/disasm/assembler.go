@@ -0,0 +1,515 @@
+package disasm
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Segment is a contiguous block of assembled bytes.
+type Segment struct {
+	Addr uint16
+	Data []byte
+}
+
+type asmLine struct {
+	num       int
+	label     string
+	mnemonic  string
+	operand   string
+	hasOrigin bool
+	equate    bool
+}
+
+type assembler struct {
+	illegal    bool
+	pass       int
+	pc         int
+	hasPC      bool
+	symbols    map[string]uint16
+	modes      map[int]string
+	unresolved bool
+	segments   []Segment
+}
+
+// Assemble translates multi-line 6502 source into memory segments. It accepts
+// labels (column one or trailing ':'), "*=" and ORG origins, "NAME = expr"
+// equates, .BYTE/.WORD/.DBYT data, the < and > byte operators and + - * /
+// expressions grouped with [ ]. Forward references are resolved in a second
+// pass; operands that are unknown in the first pass use absolute addressing.
+func Assemble(source string, illegal bool) ([]Segment, error) {
+	lines, err := parseAsmSource(source, illegal)
+	if err != nil {
+		return nil, err
+	}
+	a := &assembler{
+		illegal: illegal,
+		symbols: map[string]uint16{},
+		modes:   map[int]string{},
+	}
+	for a.pass = 1; a.pass <= 2; a.pass++ {
+		a.pc = 0
+		a.hasPC = false
+		a.segments = nil
+		for _, line := range lines {
+			if err := a.line(line); err != nil {
+				return nil, fmt.Errorf("line %d: %w", line.num, err)
+			}
+		}
+	}
+	out := make([]Segment, 0, len(a.segments))
+	for _, seg := range a.segments {
+		if len(seg.Data) > 0 {
+			out = append(out, seg)
+		}
+	}
+	return out, nil
+}
+
+func parseAsmSource(source string, illegal bool) ([]asmLine, error) {
+	table := asmOpcodesByMnemonic
+	if illegal {
+		table = asmIllegalOpcodesByMnemonic
+	}
+	isStatement := func(word string) bool {
+		_, ok := table[word]
+		return ok || strings.HasPrefix(word, ".") || isAsmDirective(word)
+	}
+	out := make([]asmLine, 0, 64)
+	for i, raw := range strings.Split(source, "\n") {
+		text := strings.TrimRight(stripAsmComment(raw), " \t\r")
+		if strings.TrimSpace(text) == "" {
+			continue
+		}
+		line := asmLine{num: i + 1}
+		indented := text[0] == ' ' || text[0] == '\t'
+		text = strings.TrimSpace(text)
+		word, rest := splitAsmStatement(text)
+		if i := strings.Index(word, "="); i > 0 && word[0] != '*' {
+			word, rest = word[:i], strings.TrimSpace(word[i:]+" "+rest)
+		}
+		upper := strings.ToUpper(word)
+		switch {
+		case strings.HasSuffix(word, ":"):
+			line.label = strings.ToUpper(strings.TrimSuffix(word, ":"))
+			text = rest
+		case strings.HasPrefix(text, "*"):
+		case strings.HasPrefix(rest, "=") || strings.EqualFold(firstAsmWord(rest), "EQU"):
+			line.label = upper
+			text = rest
+		case !indented && !isStatement(upper):
+			line.label = upper
+			text = rest
+		}
+		if line.label != "" && !isAsmSymbol(line.label) {
+			return nil, fmt.Errorf("line %d: invalid label: %s", line.num, line.label)
+		}
+		switch {
+		case strings.HasPrefix(text, "*"):
+			body := strings.TrimSpace(text[1:])
+			if !strings.HasPrefix(body, "=") {
+				return nil, fmt.Errorf("line %d: expected *= origin", line.num)
+			}
+			line.hasOrigin = true
+			line.operand = strings.TrimSpace(body[1:])
+		case strings.HasPrefix(text, "="):
+			line.equate = true
+			line.operand = strings.TrimSpace(text[1:])
+		default:
+			word, rest = splitAsmStatement(text)
+			line.mnemonic = strings.ToUpper(word)
+			line.operand = rest
+			if line.mnemonic == "EQU" {
+				line.mnemonic = ""
+				line.equate = true
+			}
+			if line.mnemonic == "ORG" || line.mnemonic == ".ORG" {
+				line.mnemonic = ""
+				line.hasOrigin = true
+			}
+		}
+		if line.equate && line.label == "" {
+			return nil, fmt.Errorf("line %d: equate without name", line.num)
+		}
+		out = append(out, line)
+	}
+	return out, nil
+}
+
+func stripAsmComment(text string) string {
+	quote := rune(0)
+	for i, r := range text {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '"' || r == '\'':
+			quote = r
+		case r == ';':
+			return text[:i]
+		}
+	}
+	return text
+}
+
+func firstAsmWord(text string) string {
+	word, _ := splitAsmStatement(text)
+	return word
+}
+
+func isAsmDirective(word string) bool {
+	switch word {
+	case "ORG", "EQU", "DB", "BYTE", "DW", "WORD", "DBYT":
+		return true
+	}
+	return false
+}
+
+func isAsmSymbol(name string) bool {
+	for i, r := range name {
+		if r == '_' || unicode.IsLetter(r) || (i > 0 && (unicode.IsDigit(r) || r == '.' || r == '@')) {
+			continue
+		}
+		return false
+	}
+	return name != ""
+}
+
+func (a *assembler) line(line asmLine) error {
+	a.unresolved = false
+	if line.equate {
+		v, err := a.eval(line.operand)
+		if err != nil {
+			return err
+		}
+		if a.unresolved {
+			// Leave forward equates undefined so users fall back to
+			// absolute addressing until the second pass.
+			return nil
+		}
+		return a.define(line.label, v)
+	}
+	if line.hasOrigin {
+		v, err := a.eval(line.operand)
+		if err != nil {
+			return err
+		}
+		if a.unresolved {
+			return fmt.Errorf("origin uses undefined symbol")
+		}
+		a.pc = int(v)
+		a.hasPC = true
+		a.segments = append(a.segments, Segment{Addr: v})
+		if line.label != "" {
+			return a.define(line.label, v)
+		}
+		return nil
+	}
+	if line.label != "" {
+		if !a.hasPC {
+			return fmt.Errorf("label before origin: %s", line.label)
+		}
+		if err := a.define(line.label, uint16(a.pc)); err != nil {
+			return err
+		}
+	}
+	if line.mnemonic == "" {
+		return nil
+	}
+	if !a.hasPC {
+		return fmt.Errorf("origin not set (use *= address)")
+	}
+	data, err := a.statement(line)
+	if err != nil {
+		return err
+	}
+	if a.pc+len(data) > 0x10000 {
+		return fmt.Errorf("code exceeds $FFFF")
+	}
+	seg := &a.segments[len(a.segments)-1]
+	seg.Data = append(seg.Data, data...)
+	a.pc += len(data)
+	return nil
+}
+
+func (a *assembler) define(name string, v uint16) error {
+	if _, ok := a.symbols[name]; ok && a.pass == 1 {
+		return fmt.Errorf("duplicate symbol: %s", name)
+	}
+	a.symbols[name] = v
+	return nil
+}
+
+func (a *assembler) statement(line asmLine) ([]byte, error) {
+	switch line.mnemonic {
+	case ".BYTE", ".BYT", ".DB", "DB", "BYTE":
+		return a.data(line.operand, 1, false)
+	case ".WORD", ".DW", "DW", "WORD":
+		return a.data(line.operand, 2, false)
+	case ".DBYT", "DBYT":
+		return a.data(line.operand, 2, true)
+	}
+	table := asmOpcodesByMnemonic
+	if a.illegal {
+		table = asmIllegalOpcodesByMnemonic
+	}
+	modes, ok := table[line.mnemonic]
+	if !ok {
+		return nil, fmt.Errorf("unknown mnemonic: %s", line.mnemonic)
+	}
+	mode, value, err := parseAsmOperand(line.operand, a.eval)
+	if err != nil {
+		return nil, err
+	}
+	if a.pass == 1 {
+		probe := value
+		if a.unresolved {
+			probe = 0xFFFF
+		}
+		resolved, _, err := resolveAsmOpcode(modes, mode, probe)
+		if err != nil && a.unresolved {
+			resolved, _, err = resolveAsmOpcode(modes, mode, 0)
+		}
+		if err != nil {
+			return nil, err
+		}
+		a.modes[line.num] = resolved
+		return make([]byte, modeSize(resolved)), nil
+	}
+	resolved := a.modes[line.num]
+	if modeSize(resolved) == 2 && resolved != "rel" && resolved != "imm" && value > 0xFF {
+		return nil, fmt.Errorf("zero page operand out of range")
+	}
+	return encodeAsmInstruction(modes[resolved], resolved, value, uint16(a.pc))
+}
+
+func (a *assembler) data(operand string, width int, bigEndian bool) ([]byte, error) {
+	items := splitAsmList(operand)
+	if len(items) == 0 {
+		return nil, fmt.Errorf("missing data")
+	}
+	out := make([]byte, 0, len(items)*width)
+	for _, item := range items {
+		if width == 1 && len(item) >= 2 && item[0] == '"' && item[len(item)-1] == '"' {
+			out = append(out, item[1:len(item)-1]...)
+			continue
+		}
+		v, err := a.eval(item)
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case width == 1:
+			if v > 0xFF && a.pass == 2 {
+				return nil, fmt.Errorf("byte out of range: %s", item)
+			}
+			out = append(out, byte(v))
+		case bigEndian:
+			out = append(out, byte(v>>8), byte(v))
+		default:
+			out = append(out, byte(v), byte(v>>8))
+		}
+	}
+	return out, nil
+}
+
+func splitAsmList(text string) []string {
+	out := make([]string, 0, 8)
+	start := 0
+	inString := false
+	for i, r := range text {
+		switch {
+		case r == '"':
+			inString = !inString
+		case r == ',' && !inString:
+			out = append(out, strings.TrimSpace(text[start:i]))
+			start = i + 1
+		}
+	}
+	if last := strings.TrimSpace(text[start:]); last != "" || len(out) > 0 {
+		out = append(out, last)
+	}
+	return out
+}
+
+// eval computes an expression. During the first pass undefined symbols
+// evaluate to zero and mark the current statement as unresolved.
+func (a *assembler) eval(text string) (uint16, error) {
+	p := &asmExpr{a: a, text: strings.TrimSpace(text)}
+	if p.text == "" {
+		return 0, fmt.Errorf("missing operand")
+	}
+	byteOp := byte(0)
+	if p.text[0] == '<' || p.text[0] == '>' {
+		byteOp = p.text[0]
+		p.pos++
+	}
+	v, err := p.sum()
+	if err != nil {
+		return 0, err
+	}
+	p.skipSpace()
+	if p.pos < len(p.text) {
+		return 0, fmt.Errorf("invalid expression: %s", text)
+	}
+	switch byteOp {
+	case '<':
+		v &= 0xFF
+	case '>':
+		v = (v >> 8) & 0xFF
+	}
+	return uint16(v), nil
+}
+
+type asmExpr struct {
+	a    *assembler
+	text string
+	pos  int
+}
+
+func (p *asmExpr) skipSpace() {
+	for p.pos < len(p.text) && (p.text[p.pos] == ' ' || p.text[p.pos] == '\t') {
+		p.pos++
+	}
+}
+
+func (p *asmExpr) sum() (int, error) {
+	v, err := p.product()
+	if err != nil {
+		return 0, err
+	}
+	for {
+		p.skipSpace()
+		if p.pos >= len(p.text) || (p.text[p.pos] != '+' && p.text[p.pos] != '-') {
+			return v & 0xFFFF, nil
+		}
+		op := p.text[p.pos]
+		p.pos++
+		rhs, err := p.product()
+		if err != nil {
+			return 0, err
+		}
+		if op == '+' {
+			v += rhs
+		} else {
+			v -= rhs
+		}
+	}
+}
+
+func (p *asmExpr) product() (int, error) {
+	v, err := p.unary()
+	if err != nil {
+		return 0, err
+	}
+	for {
+		p.skipSpace()
+		if p.pos >= len(p.text) || (p.text[p.pos] != '*' && p.text[p.pos] != '/') {
+			return v, nil
+		}
+		op := p.text[p.pos]
+		p.pos++
+		rhs, err := p.unary()
+		if err != nil {
+			return 0, err
+		}
+		if op == '*' {
+			v *= rhs
+			continue
+		}
+		if rhs == 0 {
+			if p.a.unresolved {
+				continue
+			}
+			return 0, fmt.Errorf("division by zero")
+		}
+		v /= rhs
+	}
+}
+
+func (p *asmExpr) unary() (int, error) {
+	p.skipSpace()
+	if p.pos < len(p.text) && p.text[p.pos] == '-' {
+		p.pos++
+		v, err := p.unary()
+		return -v, err
+	}
+	return p.primary()
+}
+
+func (p *asmExpr) primary() (int, error) {
+	p.skipSpace()
+	if p.pos >= len(p.text) {
+		return 0, fmt.Errorf("missing operand")
+	}
+	switch c := p.text[p.pos]; {
+	case c == '[':
+		p.pos++
+		v, err := p.sum()
+		if err != nil {
+			return 0, err
+		}
+		p.skipSpace()
+		if p.pos >= len(p.text) || p.text[p.pos] != ']' {
+			return 0, fmt.Errorf("missing ]")
+		}
+		p.pos++
+		return v, nil
+	case c == '*':
+		p.pos++
+		return p.a.pc, nil
+	case c == '$' || c == '%':
+		p.pos++
+		base := 16
+		if c == '%' {
+			base = 2
+		}
+		return p.number(base)
+	case c == '\'':
+		if p.pos+2 < len(p.text) && p.text[p.pos+2] == '\'' {
+			v := int(p.text[p.pos+1])
+			p.pos += 3
+			return v, nil
+		}
+		return 0, fmt.Errorf("invalid character literal")
+	case c >= '0' && c <= '9':
+		if len(p.text) > p.pos+1 && (p.text[p.pos+1] == 'x' || p.text[p.pos+1] == 'X') {
+			p.pos += 2
+			return p.number(16)
+		}
+		return p.number(10)
+	}
+	start := p.pos
+	for p.pos < len(p.text) && isAsmSymbol(p.text[start:p.pos+1]) {
+		p.pos++
+	}
+	name := strings.ToUpper(p.text[start:p.pos])
+	if name == "" {
+		return 0, fmt.Errorf("unexpected %q", p.text[p.pos:])
+	}
+	if v, ok := p.a.symbols[name]; ok {
+		return int(v), nil
+	}
+	if p.a.pass == 1 {
+		p.a.unresolved = true
+		return 0, nil
+	}
+	return 0, fmt.Errorf("undefined symbol: %s", name)
+}
+
+func (p *asmExpr) number(base int) (int, error) {
+	start := p.pos
+	for p.pos < len(p.text) {
+		if _, err := strconv.ParseUint(p.text[p.pos:p.pos+1], base, 8); err != nil {
+			break
+		}
+		p.pos++
+	}
+	n, err := strconv.ParseUint(p.text[start:p.pos], base, 32)
+	if err != nil || n > 0xFFFF {
+		return 0, fmt.Errorf("number out of range: %s", p.text[start:p.pos])
+	}
+	return int(n), nil
+}
@@ -0,0 +1,73 @@
+package disasm
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestAssemble(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		addr   uint16
+		want   []byte
+	}{
+		{
+			name:   "character literals keep their case",
+			source: "*=$0600\n lda #'a'\n ldx #'A'\n",
+			addr:   0x0600,
+			want:   []byte{0xA9, 0x61, 0xA2, 0x41},
+		},
+		{
+			name:   "lower case registers",
+			source: "*=$0600\n lda ($80),y\n sta $d000,x\n lda ($10,x)\n asl a\n",
+			addr:   0x0600,
+			want:   []byte{0xB1, 0x80, 0x9D, 0x00, 0xD0, 0xA1, 0x10, 0x0A},
+		},
+		{
+			name:   "forward reference and byte operators",
+			source: "*=$2000\nstart jmp done\n .byte \"Hi\", <done, >done\ndone: rts\n",
+			addr:   0x2000,
+			want:   []byte{0x4C, 0x07, 0x20, 'H', 'i', 0x07, 0x20, 0x60},
+		},
+		{
+			name:   "equates and words",
+			source: "PTR = $CB\n org $3000\n lda (PTR),Y\n .word PTR+1\n",
+			addr:   0x3000,
+			want:   []byte{0xB1, 0xCB, 0xCC, 0x00},
+		},
+		{
+			name:   "branch backwards",
+			source: "*=$0600\nloop dex\n bne loop\n",
+			addr:   0x0600,
+			want:   []byte{0xCA, 0xD0, 0xFD},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			segs, err := Assemble(tt.source, false)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(segs) != 1 {
+				t.Fatalf("got %d segments, want 1", len(segs))
+			}
+			if segs[0].Addr != tt.addr || !bytes.Equal(segs[0].Data, tt.want) {
+				t.Fatalf("got $%04X % X, want $%04X % X", segs[0].Addr, segs[0].Data, tt.addr, tt.want)
+			}
+		})
+	}
+}
+
+func TestAssembleErrors(t *testing.T) {
+	for _, source := range []string{
+		"*=$0600\n lda undefined\n",
+		"*=$0600\n lda #$100\n",
+		"*=$0600\n xyz $10\n",
+		"*=$0600\n lax $10\n",
+	} {
+		if _, err := Assemble(source, false); err == nil {
+			t.Errorf("Assemble(%q) succeeded, want error", source)
+		}
+	}
+}
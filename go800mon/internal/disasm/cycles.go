@@ -0,0 +1,93 @@
+package disasm
+
+import "fmt"
+
+// opCycles holds the NMOS 6502 base cycle counts, undocumented opcodes
+// included. JAM halts the CPU and counts as 0.
+var opCycles = [256]byte{
+	7, 6, 0, 8, 3, 3, 5, 5, 3, 2, 2, 2, 4, 4, 6, 6, // 0x00
+	2, 5, 0, 8, 4, 4, 6, 6, 2, 4, 2, 7, 4, 4, 7, 7, // 0x10
+	6, 6, 0, 8, 3, 3, 5, 5, 4, 2, 2, 2, 4, 4, 6, 6, // 0x20
+	2, 5, 0, 8, 4, 4, 6, 6, 2, 4, 2, 7, 4, 4, 7, 7, // 0x30
+	6, 6, 0, 8, 3, 3, 5, 5, 3, 2, 2, 2, 3, 4, 6, 6, // 0x40
+	2, 5, 0, 8, 4, 4, 6, 6, 2, 4, 2, 7, 4, 4, 7, 7, // 0x50
+	6, 6, 0, 8, 3, 3, 5, 5, 4, 2, 2, 2, 5, 4, 6, 6, // 0x60
+	2, 5, 0, 8, 4, 4, 6, 6, 2, 4, 2, 7, 4, 4, 7, 7, // 0x70
+	2, 6, 2, 6, 3, 3, 3, 3, 2, 2, 2, 2, 4, 4, 4, 4, // 0x80
+	2, 6, 0, 6, 4, 4, 4, 4, 2, 5, 2, 5, 5, 5, 5, 5, // 0x90
+	2, 6, 2, 6, 3, 3, 3, 3, 2, 2, 2, 2, 4, 4, 4, 4, // 0xA0
+	2, 5, 0, 5, 4, 4, 4, 4, 2, 4, 2, 4, 4, 4, 4, 4, // 0xB0
+	2, 6, 2, 8, 3, 3, 5, 5, 2, 2, 2, 2, 4, 4, 6, 6, // 0xC0
+	2, 5, 0, 8, 4, 4, 6, 6, 2, 4, 2, 7, 4, 4, 7, 7, // 0xD0
+	2, 6, 2, 8, 3, 3, 5, 5, 2, 2, 2, 2, 4, 4, 6, 6, // 0xE0
+	2, 5, 0, 8, 4, 4, 6, 6, 2, 4, 2, 7, 4, 4, 7, 7, // 0xF0
+}
+
+// pageCrossOps lists the indexed reads that take one more cycle when the
+// effective address crosses a page. Stores and read-modify-write opcodes
+// always take the long path and are counted in opCycles.
+var pageCrossOps = map[byte]struct{}{
+	0x11: {}, 0x31: {}, 0x51: {}, 0x71: {}, 0xB1: {}, 0xD1: {}, 0xF1: {}, 0xB3: {},
+	0x19: {}, 0x39: {}, 0x59: {}, 0x79: {}, 0xB9: {}, 0xD9: {}, 0xF9: {}, 0xBB: {},
+	0xBE: {}, 0xBF: {},
+	0x1D: {}, 0x3D: {}, 0x5D: {}, 0x7D: {}, 0xBD: {}, 0xDD: {}, 0xFD: {}, 0xBC: {},
+	0x1C: {}, 0x3C: {}, 0x5C: {}, 0x7C: {}, 0xDC: {}, 0xFC: {},
+}
+
+// opTiming returns the cycle fields for opcode op at pc. A page crossing
+// penalty is dropped when the base address starts a page, since no index can
+// cross it, and a taken branch costs one more cycle when target lies on
+// another page than the next instruction.
+func opTiming(op byte, mode string, pc uint16, target *uint16) (cycles, page, branch int) {
+	cycles = int(opCycles[op])
+	if mode == "rel" && target != nil {
+		branch = 1
+		if (pc+2)&0xFF00 != *target&0xFF00 {
+			branch = 2
+		}
+		return cycles, 0, branch
+	}
+	if _, ok := pageCrossOps[op]; ok && (mode == "iny" || target == nil || byte(*target) != 0) {
+		page = 1
+	}
+	return cycles, page, 0
+}
+
+// CyclesText formats the timing of ins as "4", "4+" when an indexed access
+// may cross a page, or "2/3" for a branch not taken/taken. Data rows are "".
+func (ins DecodedInstruction) CyclesText() string {
+	switch {
+	case ins.Cycles == 0:
+		return ""
+	case ins.BranchCycles > 0:
+		return fmt.Sprintf("%d/%d", ins.Cycles, ins.Cycles+ins.BranchCycles)
+	case ins.PageCycles > 0:
+		return fmt.Sprintf("%d+", ins.Cycles)
+	}
+	return fmt.Sprintf("%d", ins.Cycles)
+}
+
+// ExecCycles returns the cycles ins took when executed with index registers x
+// and y before continuing at next. ok is false when a (zp),Y page crossing
+// would need the pointer from memory.
+func (ins DecodedInstruction) ExecCycles(x, y byte, next uint16) (cycles int, ok bool) {
+	cycles = ins.Cycles
+	switch {
+	case ins.BranchCycles > 0:
+		if next != ins.Addr+2 {
+			cycles += ins.BranchCycles
+		}
+	case ins.PageCycles > 0:
+		if ins.Target == nil || ins.Addressing == "iny" {
+			return cycles, false
+		}
+		index := x
+		if ins.Addressing == "aby" {
+			index = y
+		}
+		if int(byte(*ins.Target))+int(index) > 0xFF {
+			cycles += ins.PageCycles
+		}
+	}
+	return cycles, true
+}
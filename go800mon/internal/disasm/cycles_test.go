@@ -0,0 +1,61 @@
+package disasm
+
+import "testing"
+
+func TestCyclesText(t *testing.T) {
+	tests := []struct {
+		name string
+		addr uint16
+		code []byte
+		want string
+	}{
+		{"abx may cross", 0x0600, []byte{0xBD, 0x34, 0x12}, "4+"},
+		{"abx at page start", 0x0600, []byte{0xBD, 0x00, 0x12}, "4"},
+		{"aby may cross", 0x0600, []byte{0xB9, 0xF0, 0x12}, "4+"},
+		{"iny may cross", 0x0600, []byte{0xB1, 0x80}, "5+"},
+		{"store abx", 0x0600, []byte{0x9D, 0x34, 0x12}, "5"},
+		{"inc abx", 0x0600, []byte{0xFE, 0x34, 0x12}, "7"},
+		{"asl abx", 0x0600, []byte{0x1E, 0x34, 0x12}, "7"},
+		{"sta iny", 0x0600, []byte{0x91, 0x80}, "6"},
+		{"branch same page", 0x0600, []byte{0xD0, 0x02}, "2/3"},
+		{"branch forward to next page", 0x06F0, []byte{0xD0, 0x20}, "2/4"},
+		{"branch back to previous page", 0x0600, []byte{0xD0, 0x80}, "2/4"},
+		{"data", 0x0600, []byte{0x02}, ""},
+	}
+	for _, tt := range tests {
+		ins := Decode(tt.addr, tt.code, false)[0]
+		if got := ins.CyclesText(); got != tt.want {
+			t.Errorf("%s: CyclesText(%s) = %q, want %q", tt.name, ins.AsmText, got, tt.want)
+		}
+	}
+}
+
+func TestExecCycles(t *testing.T) {
+	tests := []struct {
+		name   string
+		addr   uint16
+		code   []byte
+		x, y   byte
+		next   uint16
+		want   int
+		wantOK bool
+	}{
+		{"abx same page", 0x0600, []byte{0xBD, 0xF0, 0x12}, 0x0F, 0, 0x0603, 4, true},
+		{"abx crosses", 0x0600, []byte{0xBD, 0xF0, 0x12}, 0x10, 0, 0x0603, 5, true},
+		{"aby uses Y", 0x0600, []byte{0xB9, 0xF0, 0x12}, 0x00, 0x10, 0x0603, 5, true},
+		{"aby ignores X", 0x0600, []byte{0xB9, 0xF0, 0x12}, 0x10, 0x00, 0x0603, 4, true},
+		{"iny needs the pointer", 0x0600, []byte{0xB1, 0x80}, 0, 0x10, 0x0602, 5, false},
+		{"rmw never adds", 0x0600, []byte{0xFE, 0xF0, 0x12}, 0x20, 0, 0x0603, 7, true},
+		{"store never adds", 0x0600, []byte{0x99, 0xF0, 0x12}, 0, 0x20, 0x0603, 5, true},
+		{"branch not taken", 0x0600, []byte{0xD0, 0x02}, 0, 0, 0x0602, 2, true},
+		{"branch taken same page", 0x0600, []byte{0xD0, 0x02}, 0, 0, 0x0604, 3, true},
+		{"branch taken across page", 0x06F0, []byte{0xD0, 0x20}, 0, 0, 0x0712, 4, true},
+	}
+	for _, tt := range tests {
+		ins := Decode(tt.addr, tt.code, false)[0]
+		got, ok := ins.ExecCycles(tt.x, tt.y, tt.next)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("%s: ExecCycles(%s) = %d, %t; want %d, %t", tt.name, ins.AsmText, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
@@ -1,8 +1,11 @@
 package disasm
 
-import "fmt"
+import (
+	"fmt"
+	"strings"
 
-import "go800mon/internal/memorymap"
+	"go800mon/internal/memorymap"
+)
 
 type DecodedInstruction struct {
 	Addr           uint16
@@ -15,7 +18,13 @@ type DecodedInstruction struct {
 	AsmText        string
 	Addressing     string
 	FlowTarget     *uint16
+	Target         *uint16
 	OperandAddrPos *[2]int
+	// Cycles is the base cycle count. PageCycles is added when an indexed
+	// read crosses a page and BranchCycles when a branch is taken.
+	Cycles       int
+	PageCycles   int
+	BranchCycles int
 }
 
 var flowMnemonics = map[string]struct{}{
@@ -23,8 +32,12 @@ var flowMnemonics = map[string]struct{}{
 	"BNE": {}, "BPL": {}, "BVC": {}, "BVS": {}, "BRA": {},
 }
 
-func Disasm(startAddr uint16, data []byte) []string {
-	decoded := Decode(startAddr, data)
+func Disasm(startAddr uint16, data []byte, illegal bool) []string {
+	return Listing(Decode(startAddr, data, illegal))
+}
+
+// Listing formats decoded rows as "ADDR: BYTES ASM" lines.
+func Listing(decoded []DecodedInstruction) []string {
 	out := make([]string, 0, len(decoded))
 	for _, ins := range decoded {
 		out = append(out, fmt.Sprintf("%04X: %-8s %s", ins.Addr, ins.RawText, ins.AsmText))
@@ -32,23 +45,43 @@ func Disasm(startAddr uint16, data []byte) []string {
 	return out
 }
 
-func DisasmOne(startAddr uint16, data []byte) string {
-	ins := DecodeOne(startAddr, data)
+func DisasmOne(startAddr uint16, data []byte, illegal bool) string {
+	ins := DecodeOne(startAddr, data, illegal)
 	if ins == nil {
 		return ""
 	}
 	return fmt.Sprintf("%-8s %s", ins.RawText, ins.AsmText)
 }
 
-func DecodeOne(startAddr uint16, data []byte) *DecodedInstruction {
-	decoded := Decode(startAddr, data)
+func DecodeOne(startAddr uint16, data []byte, illegal bool) *DecodedInstruction {
+	decoded := Decode(startAddr, data, illegal)
 	if len(decoded) == 0 {
 		return nil
 	}
 	return &decoded[0]
 }
 
-func Decode(startAddr uint16, data []byte) []DecodedInstruction {
+// DecodeData renders raw as a .BYTE data row at addr.
+func DecodeData(addr uint16, raw []byte) DecodedInstruction {
+	parts := make([]string, len(raw))
+	for i, b := range raw {
+		parts[i] = fmt.Sprintf("$%02X", b)
+	}
+	operand := strings.Join(parts, ",")
+	return DecodedInstruction{
+		Addr:     addr,
+		Size:     len(raw),
+		Raw:      append([]byte(nil), raw...),
+		RawText:  fmtBytes(raw),
+		Mnemonic: ".BYTE",
+		Operand:  operand,
+		AsmText:  ".BYTE " + operand,
+	}
+}
+
+// Decode disassembles data starting at startAddr. Undocumented opcodes are
+// rendered as .DB bytes unless illegal is set.
+func Decode(startAddr uint16, data []byte, illegal bool) []DecodedInstruction {
 	if len(data) == 0 {
 		return nil
 	}
@@ -57,8 +90,7 @@ func Decode(startAddr uint16, data []byte) []DecodedInstruction {
 	out := make([]DecodedInstruction, 0, len(data)/2)
 	for consumed < len(data) {
 		op := data[consumed]
-		mn := opMnemonic[op]
-		mode := opMode[op]
+		mn, mode := opcodeInfo(op, illegal)
 		size := modeSize(mode)
 		if size < 1 {
 			size = 1
@@ -99,6 +131,10 @@ func Decode(startAddr uint16, data []byte) []DecodedInstruction {
 			v := *target
 			flowTarget = &v
 		}
+		var cycles, pageCycles, branchCycles int
+		if mn != ".DB" && size == modeSize(mode) {
+			cycles, pageCycles, branchCycles = opTiming(op, mode, pc, target)
+		}
 		out = append(out, DecodedInstruction{
 			Addr:           pc,
 			Size:           size,
@@ -110,7 +146,11 @@ func Decode(startAddr uint16, data []byte) []DecodedInstruction {
 			AsmText:        asmText,
 			Addressing:     mode,
 			FlowTarget:     flowTarget,
+			Target:         target,
 			OperandAddrPos: span,
+			Cycles:         cycles,
+			PageCycles:     pageCycles,
+			BranchCycles:   branchCycles,
 		})
 		consumed += size
 		pc = uint16((int(pc) + size) & 0xFFFF)
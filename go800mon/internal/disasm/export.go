@@ -0,0 +1,224 @@
+package disasm
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"go800mon/internal/memorymap"
+)
+
+type exportSyntax struct {
+	org         string
+	data        string
+	equ         string
+	labelSuffix string
+}
+
+var exportSyntaxes = map[string]exportSyntax{
+	"mads": {org: "org", data: ".byte", equ: "="},
+	"ca65": {org: ".org", data: ".byte", equ: "=", labelSuffix: ":"},
+	"xasm": {org: "org", data: "dta", equ: "equ"},
+}
+
+// absToZeroPage maps absolute modes to the zero-page mode an assembler picks
+// for operands below $100.
+var absToZeroPage = map[string]string{"abs": "zpg", "abx": "zpx", "aby": "zpy"}
+
+const exportBytesPerLine = 8
+
+// ExportSource renders decoded, one contiguous run of rows, as mads, ca65 or
+// xasm source that reassembles to the same bytes. Branch, JSR and operand
+// targets inside the run get labels, targets outside it use memorymap names
+// as equates. Data rows, undocumented opcodes, branches whose target wraps
+// around $0000/$FFFF and absolute operands below $100 that an assembler
+// would shorten to zero page are emitted as bytes.
+func ExportSource(decoded []DecodedInstruction, format string) (string, error) {
+	syntax, ok := exportSyntaxes[format]
+	if !ok {
+		return "", fmt.Errorf("Unknown format: %s.", format)
+	}
+	if len(decoded) == 0 {
+		return "", fmt.Errorf("Nothing to export.")
+	}
+	e := &exporter{
+		syntax:  syntax,
+		start:   int(decoded[0].Addr),
+		owner:   map[int]int{},
+		labels:  map[int]string{},
+		equates: map[uint16]string{},
+		names:   map[string]bool{},
+	}
+	e.end = e.start
+	for _, ins := range decoded {
+		if exportAsCode(ins) {
+			for i := 1; i < ins.Size; i++ {
+				e.owner[e.end+i] = e.end
+			}
+		}
+		e.end += ins.Size
+	}
+	stmts := make([]string, len(decoded))
+	for i, ins := range decoded {
+		if exportAsCode(ins) {
+			stmts[i] = e.statement(ins)
+		}
+	}
+	return e.render(decoded, stmts), nil
+}
+
+type exporter struct {
+	syntax  exportSyntax
+	start   int
+	end     int
+	owner   map[int]int
+	labels  map[int]string
+	equates map[uint16]string
+	names   map[string]bool
+}
+
+func exportAsCode(ins DecodedInstruction) bool {
+	if isDataMnemonic(ins.Mnemonic) || len(ins.Raw) != modeSize(ins.Addressing) {
+		return false
+	}
+	if _, documented := asmOpcodesByMnemonic[ins.Mnemonic]; !documented {
+		return false
+	}
+	if ins.Addressing == "rel" {
+		target := int(ins.Addr) + 2 + int(int8(ins.Raw[1]))
+		return target >= 0 && target <= 0xFFFF
+	}
+	if zp, ok := absToZeroPage[ins.Addressing]; ok && ins.Raw[2] == 0 {
+		_, shortened := asmOpcodesByMnemonic[ins.Mnemonic][zp]
+		return !shortened
+	}
+	return true
+}
+
+func (e *exporter) statement(ins DecodedInstruction) string {
+	if ins.Addressing == "acc" {
+		return ins.Mnemonic
+	}
+	operand := ins.Operand
+	if ins.Target != nil && ins.OperandAddrPos != nil {
+		span := *ins.OperandAddrPos
+		operand = operand[:span[0]] + e.reference(*ins.Target, ins.Addressing) + operand[span[1]:]
+	}
+	if operand == "" {
+		return ins.Mnemonic
+	}
+	return ins.Mnemonic + " " + operand
+}
+
+// reference names addr for an operand in mode. Zero-page operands inside the
+// run stay numeric, since a forward label would be assembled as absolute.
+func (e *exporter) reference(addr uint16, mode string) string {
+	zeroPage := mode == "zpg" || mode == "zpx" || mode == "zpy" || mode == "inx" || mode == "iny"
+	pos := int(addr)
+	if pos >= e.start && pos < e.end && !zeroPage {
+		if row, ok := e.owner[pos]; ok {
+			return fmt.Sprintf("%s+%d", e.label(row), pos-row)
+		}
+		return e.label(pos)
+	}
+	if name, ok := e.equates[addr]; ok {
+		return name
+	}
+	if sym := memorymap.Lookup(addr); sym != "" {
+		name := e.uniqueName(sym, addr)
+		e.equates[addr] = name
+		return name
+	}
+	if zeroPage {
+		return fmt.Sprintf("$%02X", addr)
+	}
+	return fmt.Sprintf("$%04X", addr)
+}
+
+func (e *exporter) label(pos int) string {
+	if name, ok := e.labels[pos]; ok {
+		return name
+	}
+	name := e.uniqueName(memorymap.Lookup(uint16(pos)), uint16(pos))
+	e.labels[pos] = name
+	return name
+}
+
+// uniqueName turns sym into a label valid in all three assemblers, falling
+// back to Lxxxx.
+func (e *exporter) uniqueName(sym string, addr uint16) string {
+	name := strings.Map(func(r rune) rune {
+		if r == '_' || (r >= 'A' && r <= 'Z') || (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			return r
+		}
+		return '_'
+	}, sym)
+	upper := strings.ToUpper(name)
+	if _, ok := asmIllegalOpcodesByMnemonic[upper]; ok || upper == "A" || upper == "X" || upper == "Y" {
+		name = "_" + name
+	}
+	if name == "" || (name[0] >= '0' && name[0] <= '9') || e.names[strings.ToUpper(name)] {
+		name = fmt.Sprintf("L%04X", addr)
+	}
+	e.names[strings.ToUpper(name)] = true
+	return name
+}
+
+func (e *exporter) render(decoded []DecodedInstruction, stmts []string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "; $%04X-$%04X\n", e.start, e.end-1)
+	addrs := make([]int, 0, len(e.equates))
+	for addr := range e.equates {
+		addrs = append(addrs, int(addr))
+	}
+	sort.Ints(addrs)
+	for _, addr := range addrs {
+		value := fmt.Sprintf("$%04X", addr)
+		if addr < 0x100 {
+			value = fmt.Sprintf("$%02X", addr)
+		}
+		fmt.Fprintf(&b, "%s %s %s\n", e.equates[uint16(addr)], e.syntax.equ, value)
+	}
+	fmt.Fprintf(&b, "\n\t%s $%04X\n", e.syntax.org, e.start)
+	var data []byte
+	dataPos := 0
+	flush := func() {
+		if len(data) == 0 {
+			return
+		}
+		parts := make([]string, len(data))
+		for i, v := range data {
+			parts[i] = fmt.Sprintf("$%02X", v)
+		}
+		e.line(&b, dataPos, e.syntax.data+" "+strings.Join(parts, ","))
+		data = nil
+	}
+	pos := e.start
+	for i, ins := range decoded {
+		if stmts[i] != "" {
+			flush()
+			e.line(&b, pos, stmts[i])
+			pos += ins.Size
+			continue
+		}
+		for _, v := range ins.Raw {
+			if _, labelled := e.labels[pos]; labelled || len(data) == exportBytesPerLine {
+				flush()
+			}
+			if len(data) == 0 {
+				dataPos = pos
+			}
+			data = append(data, v)
+			pos++
+		}
+	}
+	flush()
+	return b.String()
+}
+
+func (e *exporter) line(b *strings.Builder, pos int, stmt string) {
+	if name, ok := e.labels[pos]; ok {
+		b.WriteString(name + e.syntax.labelSuffix)
+	}
+	b.WriteString("\t" + stmt + "\n")
+}
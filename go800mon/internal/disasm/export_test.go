@@ -0,0 +1,68 @@
+package disasm
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestExportSource(t *testing.T) {
+	tests := []struct {
+		name  string
+		addr  uint16
+		code  []byte
+		want  []string
+		avoid []string
+	}{
+		{
+			name: "branch inside the run gets a label",
+			addr: 0x0600,
+			code: []byte{0xCA, 0xD0, 0xFD, 0x60},
+			want: []string{"L0600\tDEX", "\tBNE L0600", "\tRTS"},
+		},
+		{
+			name:  "branch wrapping past $FFFF is emitted as bytes",
+			addr:  0xFFFC,
+			code:  []byte{0xEA, 0xD0, 0x10, 0x60},
+			want:  []string{"\tNOP", ".byte $D0,$10"},
+			avoid: []string{"BNE"},
+		},
+		{
+			name:  "undocumented opcodes are emitted as bytes",
+			addr:  0x0600,
+			code:  []byte{0xA7, 0x80, 0x60},
+			want:  []string{".byte $A7,$80", "\tRTS"},
+			avoid: []string{"LAX"},
+		},
+		{
+			name:  "absolute operand below $100 is emitted as bytes",
+			addr:  0x0600,
+			code:  []byte{0xAD, 0x80, 0x00, 0x60},
+			want:  []string{".byte $AD,$80,$00"},
+			avoid: []string{"LDA"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			source, err := ExportSource(Decode(tt.addr, tt.code, true), "mads")
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(source, want) {
+					t.Errorf("source lacks %q:\n%s", want, source)
+				}
+			}
+			for _, avoid := range tt.avoid {
+				if strings.Contains(source, avoid) {
+					t.Errorf("source has %q:\n%s", avoid, source)
+				}
+			}
+		})
+	}
+	if _, err := ExportSource(nil, "mads"); err == nil {
+		t.Error("ExportSource(nil) succeeded")
+	}
+	if _, err := ExportSource(Decode(0x0600, []byte{0x60}, false), "tass"); err == nil {
+		t.Error("ExportSource with an unknown format succeeded")
+	}
+}
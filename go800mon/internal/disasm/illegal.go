@@ -0,0 +1,58 @@
+package disasm
+
+type illegalOp struct {
+	mnemonic string
+	mode     string
+}
+
+// illegalOps covers every NMOS 6502 opcode left as "???" in the documented
+// table, using the mnemonics common to ca65, MADS and xasm.
+var illegalOps = map[byte]illegalOp{
+	0x02: {"JAM", "imp"}, 0x12: {"JAM", "imp"}, 0x22: {"JAM", "imp"}, 0x32: {"JAM", "imp"},
+	0x42: {"JAM", "imp"}, 0x52: {"JAM", "imp"}, 0x62: {"JAM", "imp"}, 0x72: {"JAM", "imp"},
+	0x92: {"JAM", "imp"}, 0xB2: {"JAM", "imp"}, 0xD2: {"JAM", "imp"}, 0xF2: {"JAM", "imp"},
+
+	0x03: {"SLO", "inx"}, 0x07: {"SLO", "zpg"}, 0x0F: {"SLO", "abs"}, 0x13: {"SLO", "iny"},
+	0x17: {"SLO", "zpx"}, 0x1B: {"SLO", "aby"}, 0x1F: {"SLO", "abx"},
+	0x23: {"RLA", "inx"}, 0x27: {"RLA", "zpg"}, 0x2F: {"RLA", "abs"}, 0x33: {"RLA", "iny"},
+	0x37: {"RLA", "zpx"}, 0x3B: {"RLA", "aby"}, 0x3F: {"RLA", "abx"},
+	0x43: {"SRE", "inx"}, 0x47: {"SRE", "zpg"}, 0x4F: {"SRE", "abs"}, 0x53: {"SRE", "iny"},
+	0x57: {"SRE", "zpx"}, 0x5B: {"SRE", "aby"}, 0x5F: {"SRE", "abx"},
+	0x63: {"RRA", "inx"}, 0x67: {"RRA", "zpg"}, 0x6F: {"RRA", "abs"}, 0x73: {"RRA", "iny"},
+	0x77: {"RRA", "zpx"}, 0x7B: {"RRA", "aby"}, 0x7F: {"RRA", "abx"},
+	0x83: {"SAX", "inx"}, 0x87: {"SAX", "zpg"}, 0x8F: {"SAX", "abs"}, 0x97: {"SAX", "zpy"},
+	0xA3: {"LAX", "inx"}, 0xA7: {"LAX", "zpg"}, 0xAF: {"LAX", "abs"}, 0xB3: {"LAX", "iny"},
+	0xB7: {"LAX", "zpy"}, 0xBF: {"LAX", "aby"}, 0xAB: {"LAX", "imm"},
+	0xC3: {"DCP", "inx"}, 0xC7: {"DCP", "zpg"}, 0xCF: {"DCP", "abs"}, 0xD3: {"DCP", "iny"},
+	0xD7: {"DCP", "zpx"}, 0xDB: {"DCP", "aby"}, 0xDF: {"DCP", "abx"},
+	0xE3: {"ISC", "inx"}, 0xE7: {"ISC", "zpg"}, 0xEF: {"ISC", "abs"}, 0xF3: {"ISC", "iny"},
+	0xF7: {"ISC", "zpx"}, 0xFB: {"ISC", "aby"}, 0xFF: {"ISC", "abx"},
+
+	0x0B: {"ANC", "imm"}, 0x2B: {"ANC", "imm"}, 0x4B: {"ALR", "imm"}, 0x6B: {"ARR", "imm"},
+	0x8B: {"ANE", "imm"}, 0xCB: {"SBX", "imm"}, 0xEB: {"SBC", "imm"},
+	0x93: {"SHA", "iny"}, 0x9F: {"SHA", "aby"}, 0x9B: {"TAS", "aby"}, 0x9C: {"SHY", "abx"},
+	0x9E: {"SHX", "aby"}, 0xBB: {"LAS", "aby"},
+
+	0x1A: {"NOP", "imp"}, 0x3A: {"NOP", "imp"}, 0x5A: {"NOP", "imp"}, 0x7A: {"NOP", "imp"},
+	0xDA: {"NOP", "imp"}, 0xFA: {"NOP", "imp"},
+	0x80: {"NOP", "imm"}, 0x82: {"NOP", "imm"}, 0x89: {"NOP", "imm"}, 0xC2: {"NOP", "imm"},
+	0xE2: {"NOP", "imm"},
+	0x04: {"NOP", "zpg"}, 0x44: {"NOP", "zpg"}, 0x64: {"NOP", "zpg"},
+	0x14: {"NOP", "zpx"}, 0x34: {"NOP", "zpx"}, 0x54: {"NOP", "zpx"}, 0x74: {"NOP", "zpx"},
+	0xD4: {"NOP", "zpx"}, 0xF4: {"NOP", "zpx"},
+	0x0C: {"NOP", "abs"},
+	0x1C: {"NOP", "abx"}, 0x3C: {"NOP", "abx"}, 0x5C: {"NOP", "abx"}, 0x7C: {"NOP", "abx"},
+	0xDC: {"NOP", "abx"}, 0xFC: {"NOP", "abx"},
+}
+
+// opcodeInfo returns the mnemonic and addressing mode for op. Undocumented
+// opcodes resolve to "???" unless illegal is set.
+func opcodeInfo(op byte, illegal bool) (string, string) {
+	mn := opMnemonic[op]
+	if mn == "???" && illegal {
+		if ill, ok := illegalOps[op]; ok {
+			return ill.mnemonic, ill.mode
+		}
+	}
+	return mn, opMode[op]
+}
@@ -0,0 +1,61 @@
+package disasm
+
+import (
+	"bytes"
+	"testing"
+)
+
+// TestIllegalOpsRoundTrip decodes every undocumented opcode and assembles
+// the listing again. Opcodes that duplicate another one, like the NOP and
+// JAM variants or SBC $EB, come back as the canonical opcode of the same
+// instruction.
+func TestIllegalOpsRoundTrip(t *testing.T) {
+	if len(illegalOps) != 105 {
+		t.Fatalf("illegalOps has %d entries, want 105", len(illegalOps))
+	}
+	canonical := 0
+	for op, ill := range illegalOps {
+		raw := []byte{op, 0x34, 0x12}[:modeSize(ill.mode)]
+		ins := Decode(0x0600, raw, true)[0]
+		if ins.Mnemonic != ill.mnemonic || ins.Size != len(raw) {
+			t.Errorf("$%02X decodes as %s (%d bytes), want %s", op, ins.AsmText, ins.Size, ill.mnemonic)
+			continue
+		}
+		got, err := AssembleOne(0x0600, ins.AsmText, true)
+		if err != nil {
+			t.Errorf("$%02X %s: %v", op, ins.AsmText, err)
+			continue
+		}
+		if bytes.Equal(got, raw) {
+			canonical++
+			continue
+		}
+		if again := Decode(0x0600, got, true)[0]; again.AsmText != ins.AsmText || again.Size != ins.Size {
+			t.Errorf("$%02X %s assembles to % X, which decodes as %s", op, ins.AsmText, got, again.AsmText)
+		}
+	}
+	// 105 opcodes minus the 35 NOP, JAM, SBC and ANC duplicates.
+	if canonical != 70 {
+		t.Errorf("%d opcodes reassemble to themselves, want 70", canonical)
+	}
+}
+
+func TestIllegalOpsWithoutIllegal(t *testing.T) {
+	for op, ill := range illegalOps {
+		raw := []byte{op, 0x34, 0x12}[:modeSize(ill.mode)]
+		ins := Decode(0x0600, raw, false)[0]
+		if ins.Size != 1 || !isDataMnemonic(ins.Mnemonic) {
+			t.Errorf("$%02X decodes as %s without illegal, want a data byte", op, ins.AsmText)
+			continue
+		}
+		if got, err := AssembleOne(0x0600, ins.AsmText, false); err != nil || !bytes.Equal(got, raw[:1]) {
+			t.Errorf("$%02X %s assembles to % X, %v", op, ins.AsmText, got, err)
+		}
+		text := Decode(0x0600, raw, true)[0].AsmText
+		if got, err := AssembleOne(0x0600, text, false); err == nil {
+			if again := Decode(0x0600, got, false)[0]; again.AsmText != text {
+				t.Errorf("%s assembles without illegal to % X (%s)", text, got, again.AsmText)
+			}
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package disasm
+
+// Xref is one instruction referencing an address. Kind is "call", "jump",
+// "branch", "read", "write" or "modify".
+type Xref struct {
+	Kind string
+	Ins  DecodedInstruction
+}
+
+// XrefIndex maps target addresses to the instructions referencing them.
+type XrefIndex map[uint16][]Xref
+
+var (
+	xrefWrites = map[string]struct{}{
+		"STA": {}, "STX": {}, "STY": {}, "SAX": {}, "SHA": {}, "SHX": {}, "SHY": {}, "TAS": {},
+	}
+	xrefModifies = map[string]struct{}{
+		"ASL": {}, "LSR": {}, "ROL": {}, "ROR": {}, "INC": {}, "DEC": {},
+		"SLO": {}, "RLA": {}, "SRE": {}, "RRA": {}, "DCP": {}, "ISC": {},
+	}
+)
+
+// BuildXrefs indexes the branch, JSR and JMP targets and the memory operands
+// of decoded. Indexed operands count as references to their base address and
+// (zp,X)/(zp),Y operands as reads of the pointer.
+func BuildXrefs(decoded []DecodedInstruction) XrefIndex {
+	index := XrefIndex{}
+	for _, ins := range decoded {
+		if ins.Target == nil {
+			continue
+		}
+		index[*ins.Target] = append(index[*ins.Target], Xref{Kind: xrefKind(ins), Ins: ins})
+	}
+	return index
+}
+
+func xrefKind(ins DecodedInstruction) string {
+	switch {
+	case ins.Addressing == "rel":
+		return "branch"
+	case ins.Mnemonic == "JSR":
+		return "call"
+	case ins.Mnemonic == "JMP" && ins.Addressing == "abs":
+		return "jump"
+	case ins.Addressing == "inx" || ins.Addressing == "iny" || ins.Addressing == "ind":
+		return "read"
+	}
+	if _, ok := xrefWrites[ins.Mnemonic]; ok {
+		return "write"
+	}
+	if _, ok := xrefModifies[ins.Mnemonic]; ok {
+		return "modify"
+	}
+	return "read"
+}
@@ -0,0 +1,114 @@
+package displaylist
+
+import "fmt"
+
+// Issue is a hardware pitfall found at a display list entry.
+type Issue struct {
+	Addr    uint16
+	Message string
+}
+
+const visibleLines = 240
+
+// ModeLines is the number of scanlines per mode line, without vertical
+// scrolling.
+var ModeLines = [16]int{2: 8, 3: 10, 4: 8, 5: 16, 6: 8, 7: 16, 8: 8, 9: 4, 0xA: 4, 0xB: 2, 0xC: 1, 0xD: 2, 0xE: 1, 0xF: 1}
+
+// Lint checks d for mistakes ANTIC does not forgive: screen memory or the
+// display list crossing the 4K/1K boundary of their address counters, a
+// missing JVB, more lines than fit the frame, unterminated VSCROL regions,
+// HSCROL width changes without LMS and DLIs while NMIEN masks them. pal
+// selects the 312-line PAL frame instead of the 262-line NTSC one.
+func (d DisplayList) Lint(dmactl, nmien byte, pal bool) []Issue {
+	var issues []Issue
+	report := func(addr uint16, format string, args ...any) {
+		issues = append(issues, Issue{Addr: addr, Message: fmt.Sprintf(format, args...)})
+	}
+	if len(d.Entries) == 0 {
+		return nil
+	}
+	mapper := NewMemoryMapper(d, dmactl, 0)
+	width := mapper.widthBytes()
+	dlBlock := d.Entries[0].Addr & 0xFC00
+	afterJump := false
+	var screen, screenBlock uint16
+	hasScreen := false
+	lines := 0
+	prevHScroll := false
+	var vscrollStart *Entry
+	for i, e := range d.Entries {
+		mode := e.Mode()
+		size := uint16(1)
+		if mode == 1 || (mode != 0 && e.Command&0x40 != 0) {
+			size = 3
+		}
+		if afterJump {
+			dlBlock = e.Addr & 0xFC00
+			afterJump = false
+		}
+		if (e.Addr+size-1)&0xFC00 != dlBlock {
+			report(e.Addr, "Display list crosses the 1K boundary at %04X; split it with JMP.", (e.Addr+size-1)&0xFC00)
+			dlBlock = (e.Addr + size - 1) & 0xFC00
+		}
+		if e.IsDLI() && nmien&0x80 == 0 {
+			report(e.Addr, "DLI bit set but NMIEN does not enable DLIs.")
+		}
+		if mode == 1 {
+			if vscrollStart != nil {
+				report(vscrollStart.Addr, "VSCROL region is not closed by a line without VSCROL.")
+				vscrollStart = nil
+			}
+			if e.Command&0x40 != 0 {
+				break
+			}
+			afterJump = true
+			continue
+		}
+		if mode == 0 {
+			lines += int(e.Command>>4&0x07) + 1
+			continue
+		}
+		lines += ModeLines[mode]
+		vscroll := e.Command&0x20 != 0
+		if vscroll && vscrollStart == nil {
+			vscrollStart = &d.Entries[i]
+		} else if !vscroll {
+			vscrollStart = nil
+		}
+		hscroll := e.Command&0x10 != 0
+		lineWidth := width
+		if hscroll {
+			lineWidth = mapper.hscrolWidthBytes(width)
+		}
+		n := uint16(mapper.bytesPerLine(mode, lineWidth))
+		if e.Command&0x40 != 0 {
+			screen, screenBlock, hasScreen = e.Arg, e.Arg&0xF000, true
+		} else if hasScreen && hscroll != prevHScroll {
+			report(e.Addr, "HSCROL changes the line width without LMS; following rows are misaligned.")
+		}
+		prevHScroll = hscroll
+		if !hasScreen {
+			continue
+		}
+		if end := screen + n - 1; n > 0 && end&0xF000 != screenBlock {
+			report(e.Addr, "Screen memory crosses the 4K boundary at %04X without LMS.", end&0xF000)
+			screenBlock = end & 0xF000
+		}
+		screen += n
+	}
+	last := d.Entries[len(d.Entries)-1]
+	if last.Mode() != 1 || last.Command&0x40 == 0 {
+		report(last.Addr, "Display list does not end with JVB.")
+	}
+	frame, tv := 262, "NTSC"
+	if pal {
+		frame, tv = 312, "PAL"
+	}
+	switch {
+	case lines > frame-FirstScanline:
+		report(d.StartAddr, "%d scanlines overrun the %d-line %s frame.", lines, frame, tv)
+	case lines > visibleLines:
+		report(d.StartAddr, "%d scanlines exceed the %d visible lines; the rest falls into vertical blank.", lines, visibleLines)
+	}
+	return issues
+}
@@ -0,0 +1,95 @@
+package displaylist
+
+// FirstScanline is the scanline on which ANTIC starts the display list.
+const FirstScanline = 8
+
+// Scanline is one TV line produced by a display list entry.
+type Scanline struct {
+	Line  int
+	Entry Entry
+	// Row is the line within the mode line, after VSCROL.
+	Row int
+	// DLI is set on the last line of a mode line with the DLI bit; the
+	// interrupt fires at its end.
+	DLI bool
+	// DMA estimates the CPU cycles ANTIC steals on the line: memory
+	// refresh, player/missile, display list and playfield fetches.
+	DMA int
+}
+
+// Scanlines lays out d line by line up to JVB. vscrol is the VSCROL register:
+// the first line of a VSCROL region starts at that row and the line closing
+// the region ends at it.
+func (d DisplayList) Scanlines(dmactl, vscrol byte) []Scanline {
+	mapper := NewMemoryMapper(d, dmactl, 0)
+	width := mapper.widthBytes()
+	dlDMA := dmactl&0x20 != 0
+	playfield := dlDMA && dmactl&0x03 != 0
+	// Memory refresh takes 9 cycles on every line.
+	base := 9
+	if dmactl&0x0C != 0 {
+		base++
+	}
+	if dmactl&0x08 != 0 {
+		base += 4
+	}
+	var out []Scanline
+	line := FirstScanline
+	inVScroll := false
+	for _, e := range d.Entries {
+		mode := e.Mode()
+		first, rows := 0, ModeLines[mode]
+		fetch := 0
+		switch mode {
+		case 0:
+			rows = int(e.Command>>4&0x07) + 1
+		case 1:
+			rows = 1
+		default:
+			vscroll := e.Command&0x20 != 0
+			switch {
+			case vscroll && !inVScroll:
+				first = int(vscrol & 0x0F)
+				rows = (rows-1-first)&0x0F + 1
+			case !vscroll && inVScroll:
+				rows = int(vscrol&0x0F) + 1
+			}
+			inVScroll = vscroll
+			lineWidth := width
+			if e.Command&0x10 != 0 {
+				lineWidth = mapper.hscrolWidthBytes(width)
+			}
+			fetch = mapper.bytesPerLine(mode, lineWidth)
+		}
+		for row := 0; row < rows; row++ {
+			dma := base
+			if dlDMA && row == 0 {
+				dma++
+				if mode == 1 || (mode != 0 && e.Command&0x40 != 0) {
+					dma += 2
+				}
+			}
+			if playfield {
+				if row == 0 {
+					dma += fetch
+				}
+				// Character modes fetch glyph data on every line.
+				if mode >= 2 && mode <= 7 {
+					dma += fetch
+				}
+			}
+			out = append(out, Scanline{
+				Line:  line,
+				Entry: e,
+				Row:   (first + row) & 0x0F,
+				DLI:   e.IsDLI() && row == rows-1,
+				DMA:   dma,
+			})
+			line++
+		}
+		if mode == 1 && e.Command&0x40 != 0 {
+			break
+		}
+	}
+	return out
+}
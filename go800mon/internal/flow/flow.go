@@ -0,0 +1,147 @@
+// Package flow classifies 6502 memory as code or data by following control
+// flow from known entry points.
+package flow
+
+import "go800mon/internal/disasm"
+
+// Kind classifies one byte of the address space.
+type Kind byte
+
+const (
+	Data    Kind = iota
+	Code         // first byte of an instruction
+	Operand      // operand byte of an instruction
+)
+
+// dataRowBytes is the number of bytes rendered per .BYTE row, counted from
+// the start of each data run.
+const dataRowBytes = 3
+
+// flowEnds lists instructions after which execution does not fall through.
+var flowEnds = map[string]struct{}{
+	"JMP": {}, "RTS": {}, "RTI": {}, "BRK": {}, "JAM": {}, "BRA": {},
+}
+
+// Map is the code/data classification of the whole 64K address space.
+type Map struct {
+	kinds   [0x10000]Kind
+	illegal bool
+}
+
+// VectorEntries returns the NMI, RESET and IRQ handler addresses from mem.
+func VectorEntries(mem []byte) []uint16 {
+	return []uint16{word(mem, 0xFFFA), word(mem, 0xFFFC), word(mem, 0xFFFE)}
+}
+
+// Analyze follows JMP, JSR and branch targets from entries through mem, which
+// must cover the full address space. Bytes never reached as code are data.
+func Analyze(mem []byte, entries []uint16, illegal bool) *Map {
+	m := &Map{illegal: illegal}
+	work := append([]uint16(nil), entries...)
+	for len(work) > 0 {
+		pc := work[len(work)-1]
+		work = work[:len(work)-1]
+		for m.kinds[pc] == Data {
+			raw := []byte{mem[pc], mem[pc+1], mem[pc+2]}
+			ins := disasm.DecodeOne(pc, raw, illegal)
+			if ins.Mnemonic == ".DB" || !m.free(pc, ins.Size) {
+				break
+			}
+			m.kinds[pc] = Code
+			for i := 1; i < ins.Size; i++ {
+				m.kinds[pc+uint16(i)] = Operand
+			}
+			if ins.FlowTarget != nil {
+				target := *ins.FlowTarget
+				if ins.Addressing == "ind" {
+					// JMP ($xxFF) fetches the high byte from $xx00.
+					hi := target&0xFF00 | uint16(byte(target)+1)
+					target = uint16(mem[target]) | uint16(mem[hi])<<8
+				}
+				work = append(work, target)
+			}
+			if _, ok := flowEnds[ins.Mnemonic]; ok {
+				break
+			}
+			pc += uint16(ins.Size)
+		}
+	}
+	return m
+}
+
+func (m *Map) free(addr uint16, size int) bool {
+	for i := 0; i < size; i++ {
+		if m.kinds[addr+uint16(i)] != Data {
+			return false
+		}
+	}
+	return true
+}
+
+// Kind returns the classification of addr.
+func (m *Map) Kind(addr uint16) Kind {
+	return m.kinds[addr]
+}
+
+// RowStart returns the first address of the listing row containing addr.
+func (m *Map) RowStart(addr uint16) uint16 {
+	switch m.kinds[addr] {
+	case Code:
+		return addr
+	case Operand:
+		for m.kinds[addr] == Operand && addr > 0 {
+			addr--
+		}
+		return addr
+	}
+	run := addr
+	for run > 0 && m.kinds[run-1] == Data {
+		run--
+	}
+	return addr - (addr-run)%dataRowBytes
+}
+
+// Prev returns the start of the row n rows above addr, stopping at $0000.
+func (m *Map) Prev(addr uint16, n int) uint16 {
+	for ; n > 0 && addr > 0; n-- {
+		addr = m.RowStart(addr - 1)
+	}
+	return addr
+}
+
+// Decode lists data read from start, decoding instructions at code
+// addresses and grouping everything else into .BYTE rows.
+func (m *Map) Decode(start uint16, data []byte) []disasm.DecodedInstruction {
+	out := make([]disasm.DecodedInstruction, 0, len(data)/2)
+	runStart := m.RowStart(start)
+	for pos := 0; pos < len(data); {
+		addr := start + uint16(pos)
+		kind := m.kinds[addr]
+		if kind == Code {
+			ins := disasm.DecodeOne(addr, data[pos:], m.illegal)
+			if ins.Mnemonic != ".DB" {
+				out = append(out, *ins)
+				pos += ins.Size
+				continue
+			}
+		}
+		limit := len(data) - pos
+		if kind == Data {
+			if pos > 0 && m.kinds[addr-1] != Data {
+				runStart = addr
+			}
+			limit = min(limit, dataRowBytes-int(addr-runStart)%dataRowBytes)
+		}
+		n := 1
+		for n < limit && m.kinds[addr+uint16(n)] == kind && kind != Code {
+			n++
+		}
+		out = append(out, disasm.DecodeData(addr, data[pos:pos+n]))
+		pos += n
+	}
+	return out
+}
+
+func word(mem []byte, addr uint16) uint16 {
+	return uint16(mem[addr]) | uint16(mem[addr+1])<<8
+}
@@ -0,0 +1,193 @@
+package flow
+
+import "testing"
+
+// image returns a 64K memory image with code loaded at addr.
+func image(addr uint16, code ...byte) []byte {
+	mem := make([]byte, 0x10000)
+	copy(mem[addr:], code)
+	return mem
+}
+
+// kinds renders n classifications from start: C code, o operand, . data.
+func kinds(m *Map, start uint16, n int) string {
+	out := make([]byte, n)
+	for i := range out {
+		switch m.Kind(start + uint16(i)) {
+		case Code:
+			out[i] = 'C'
+		case Operand:
+			out[i] = 'o'
+		default:
+			out[i] = '.'
+		}
+	}
+	return string(out)
+}
+
+func TestAnalyze(t *testing.T) {
+	tests := []struct {
+		name    string
+		mem     []byte
+		entries []uint16
+		illegal bool
+		start   uint16
+		want    string
+	}{
+		{
+			name:    "stops at RTS",
+			mem:     image(0x0600, 0xA9, 0x01, 0x60, 0xA9, 0x02),
+			entries: []uint16{0x0600},
+			start:   0x0600,
+			want:    "CoC..",
+		},
+		{
+			name:    "follows both sides of a branch",
+			mem:     image(0x0600, 0xF0, 0x02, 0x60, 0xFF, 0xEA, 0x60),
+			entries: []uint16{0x0600},
+			start:   0x0600,
+			want:    "CoC.CC",
+		},
+		{
+			name:    "JSR returns to the next instruction",
+			mem:     image(0x0600, 0x20, 0x06, 0x06, 0x60, 0xFF, 0xFF, 0x60),
+			entries: []uint16{0x0600},
+			start:   0x0600,
+			want:    "CooC..C",
+		},
+		{
+			name:    "JMP does not fall through",
+			mem:     image(0x0600, 0x4C, 0x05, 0x06, 0xA9, 0x00, 0x60),
+			entries: []uint16{0x0600},
+			start:   0x0600,
+			want:    "Coo..C",
+		},
+		{
+			name:    "JMP indirect follows the vector",
+			mem:     image(0x0600, 0x6C, 0x03, 0x06, 0x05, 0x06, 0x60),
+			entries: []uint16{0x0600},
+			start:   0x0600,
+			want:    "Coo..C",
+		},
+		{
+			name:    "JAM stops with illegal opcodes",
+			mem:     image(0x0600, 0xEA, 0x02, 0xEA),
+			entries: []uint16{0x0600},
+			illegal: true,
+			start:   0x0600,
+			want:    "CC.",
+		},
+		{
+			name:    "undocumented opcode is data without illegal",
+			mem:     image(0x0600, 0xEA, 0xA7, 0x80, 0x60),
+			entries: []uint16{0x0600},
+			start:   0x0600,
+			want:    "C...",
+		},
+		{
+			name:    "every entry point is followed",
+			mem:     image(0x0600, 0x60, 0xFF, 0x60, 0xFF, 0x60),
+			entries: []uint16{0x0602, 0x0604},
+			start:   0x0600,
+			want:    "..C.C",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := Analyze(tt.mem, tt.entries, tt.illegal)
+			if got := kinds(m, tt.start, len(tt.want)); got != tt.want {
+				t.Fatalf("kinds = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVectorEntries(t *testing.T) {
+	mem := image(0xFFFA, 0x00, 0xE0, 0x00, 0xE1, 0x00, 0xE2)
+	mem[0xE100] = 0x60
+	got := VectorEntries(mem)
+	if len(got) != 3 || got[0] != 0xE000 || got[1] != 0xE100 || got[2] != 0xE200 {
+		t.Fatalf("VectorEntries = %04X", got)
+	}
+	if m := Analyze(mem, got, false); m.Kind(0xE100) != Code || m.Kind(0xE101) != Data {
+		t.Fatal("RESET handler was not analysed as code")
+	}
+}
+
+// rowsImage is LDA #1 / RTS followed by eight data bytes at $0600.
+func rowsImage() ([]byte, *Map) {
+	mem := image(0x0600, 0xA9, 0x01, 0x60, 1, 2, 3, 4, 5, 6, 7, 8)
+	return mem, Analyze(mem, []uint16{0x0600}, false)
+}
+
+func TestRowStartAndPrev(t *testing.T) {
+	_, m := rowsImage()
+	for addr, want := range map[uint16]uint16{
+		0x0600: 0x0600,
+		0x0601: 0x0600,
+		0x0602: 0x0602,
+		0x0603: 0x0603,
+		0x0605: 0x0603,
+		0x0607: 0x0606,
+		0x060A: 0x0609,
+		0x0001: 0x0000,
+	} {
+		if got := m.RowStart(addr); got != want {
+			t.Errorf("RowStart($%04X) = $%04X, want $%04X", addr, got, want)
+		}
+	}
+	for _, tt := range []struct {
+		addr uint16
+		n    int
+		want uint16
+	}{
+		{0x0609, 1, 0x0606},
+		{0x0609, 2, 0x0603},
+		{0x0609, 3, 0x0602},
+		{0x0609, 4, 0x0600},
+		{0x0602, 0, 0x0602},
+		{0x0002, 5, 0x0000},
+	} {
+		if got := m.Prev(tt.addr, tt.n); got != tt.want {
+			t.Errorf("Prev($%04X, %d) = $%04X, want $%04X", tt.addr, tt.n, got, tt.want)
+		}
+	}
+}
+
+func TestDecode(t *testing.T) {
+	mem, m := rowsImage()
+	type row struct {
+		addr     uint16
+		size     int
+		mnemonic string
+	}
+	tests := []struct {
+		start uint16
+		end   uint16
+		want  []row
+	}{
+		{0x0600, 0x060A, []row{
+			{0x0600, 2, "LDA"}, {0x0602, 1, "RTS"},
+			{0x0603, 3, ".BYTE"}, {0x0606, 3, ".BYTE"}, {0x0609, 2, ".BYTE"},
+		}},
+		// Starting inside a data run keeps the rows aligned to the run.
+		{0x0604, 0x060A, []row{
+			{0x0604, 2, ".BYTE"}, {0x0606, 3, ".BYTE"}, {0x0609, 2, ".BYTE"},
+		}},
+		// Starting on an operand lists it as data up to the next code.
+		{0x0601, 0x0602, []row{
+			{0x0601, 1, ".BYTE"}, {0x0602, 1, "RTS"},
+		}},
+	}
+	for _, tt := range tests {
+		got := m.Decode(tt.start, mem[tt.start:int(tt.end)+1])
+		if len(got) != len(tt.want) {
+			t.Fatalf("Decode($%04X) gave %d rows, want %d", tt.start, len(got), len(tt.want))
+		}
+		for i, want := range tt.want {
+			if got[i].Addr != want.addr || got[i].Size != want.size || got[i].Mnemonic != want.mnemonic {
+				t.Errorf("Decode($%04X) row %d = $%04X %d %s, want $%04X %d %s", tt.start, i, got[i].Addr, got[i].Size, got[i].Mnemonic, want.addr, want.size, want.mnemonic)
+			}
+		}
+	}
+}
@@ -0,0 +1,153 @@
+package memory
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"go800mon/internal/memorymap"
+)
+
+// AddrSource supplies live emulator values for register names and [vector]
+// indirection in address expressions.
+type AddrSource interface {
+	Register(name string) (uint16, error)
+	ReadWord(addr uint16) (uint16, error)
+}
+
+var addrRegisters = map[string]struct{}{
+	"PC": {}, "S": {}, "X": {}, "Y": {}, "P": {},
+}
+
+// ParseAddr evaluates an address expression. Terms are hex numbers ($NNNN,
+// 0xNNNN or bare NNNN), decimal numbers (#123), registers (PC, S, X, Y, P),
+// memorymap symbol names and [expr] word reads, joined with + and -. Bare
+// hex wins over a symbol with the same spelling. src may be nil when no
+// emulator connection is available.
+func ParseAddr(text string, src AddrSource) (uint16, error) {
+	p := addrParser{text: strings.ToUpper(strings.TrimSpace(text)), src: src}
+	if p.text == "" {
+		return 0, fmt.Errorf("Address is empty.")
+	}
+	v, err := p.expr()
+	if err != nil {
+		return 0, err
+	}
+	if p.pos < len(p.text) {
+		return 0, fmt.Errorf("Invalid address expression: %s", text)
+	}
+	return uint16(v), nil
+}
+
+type addrParser struct {
+	text string
+	pos  int
+	src  AddrSource
+}
+
+func (p *addrParser) skipSpace() {
+	for p.pos < len(p.text) && p.text[p.pos] == ' ' {
+		p.pos++
+	}
+}
+
+func (p *addrParser) expr() (int, error) {
+	v, err := p.term()
+	if err != nil {
+		return 0, err
+	}
+	for {
+		p.skipSpace()
+		if p.pos >= len(p.text) || (p.text[p.pos] != '+' && p.text[p.pos] != '-') {
+			return v & 0xFFFF, nil
+		}
+		op := p.text[p.pos]
+		p.pos++
+		rhs, err := p.term()
+		if err != nil {
+			return 0, err
+		}
+		if op == '+' {
+			v += rhs
+		} else {
+			v -= rhs
+		}
+	}
+}
+
+func (p *addrParser) term() (int, error) {
+	p.skipSpace()
+	if p.pos >= len(p.text) {
+		return 0, fmt.Errorf("Missing address term: %s", p.text)
+	}
+	switch p.text[p.pos] {
+	case '[':
+		p.pos++
+		addr, err := p.expr()
+		if err != nil {
+			return 0, err
+		}
+		p.skipSpace()
+		if p.pos >= len(p.text) || p.text[p.pos] != ']' {
+			return 0, fmt.Errorf("Missing ] in address expression: %s", p.text)
+		}
+		p.pos++
+		if p.src == nil {
+			return 0, fmt.Errorf("[vector] needs an emulator connection: %s", p.text)
+		}
+		v, err := p.src.ReadWord(uint16(addr))
+		return int(v), err
+	case '#':
+		p.pos++
+		word := p.word()
+		n, err := strconv.ParseUint(word, 10, 16)
+		if err != nil {
+			return 0, fmt.Errorf("Invalid decimal value: #%s", word)
+		}
+		return int(n), nil
+	case '$':
+		p.pos++
+		return p.hex(p.word())
+	}
+	word := p.word()
+	if word == "" {
+		return 0, fmt.Errorf("Invalid address expression: %s", p.text)
+	}
+	if _, ok := addrRegisters[word]; ok {
+		if p.src == nil {
+			return 0, fmt.Errorf("Register %s needs an emulator connection.", word)
+		}
+		v, err := p.src.Register(word)
+		return int(v), err
+	}
+	if strings.HasPrefix(word, "0X") {
+		return p.hex(word[2:])
+	}
+	if n, err := strconv.ParseUint(word, 16, 16); err == nil {
+		return int(n), nil
+	}
+	if addr, ok := memorymap.LookupName(word); ok {
+		return int(addr), nil
+	}
+	return 0, fmt.Errorf("Unknown symbol: %s", word)
+}
+
+func (p *addrParser) word() string {
+	start := p.pos
+	for p.pos < len(p.text) {
+		c := p.text[p.pos]
+		if c == '+' || c == '-' || c == '[' || c == ']' || c == ' ' {
+			break
+		}
+		p.pos++
+	}
+	return p.text[start:p.pos]
+}
+
+func (p *addrParser) hex(word string) (int, error) {
+	n, err := strconv.ParseUint(word, 16, 16)
+	if err != nil {
+		return 0, fmt.Errorf("Invalid hex value: %s", word)
+	}
+	return int(n), nil
+}
@@ -0,0 +1,80 @@
+package memory
+
+import (
+	"fmt"
+	"testing"
+)
+
+// stubSource has PC=$2000, X=$05 and a word at $02E0 pointing to $3000.
+type stubSource struct{}
+
+func (stubSource) Register(name string) (uint16, error) {
+	switch name {
+	case "PC":
+		return 0x2000, nil
+	case "X":
+		return 0x05, nil
+	case "S":
+		return 0xF0, nil
+	}
+	return 0, fmt.Errorf("no register %s", name)
+}
+
+func (stubSource) ReadWord(addr uint16) (uint16, error) {
+	if addr == 0x02E0 {
+		return 0x3000, nil
+	}
+	return 0, nil
+}
+
+func TestParseAddr(t *testing.T) {
+	tests := []struct {
+		text string
+		want uint16
+	}{
+		{"0600", 0x0600},
+		{"$0600", 0x0600},
+		{"0x0600", 0x0600},
+		{"#1536", 0x0600},
+		// FADD is a floating point ROM routine, but bare hex wins.
+		{"FADD", 0xFADD},
+		{"runad", 0x02E0},
+		{"RUNAD+1", 0x02E1},
+		{"CH - #2", 0x02FA},
+		{"S+$101", 0x01F1},
+		{"PC+X", 0x2005},
+		{"[RUNAD]", 0x3000},
+		{"[RUNAD]+$10", 0x3010},
+		{"FFFF+2", 0x0001},
+	}
+	for _, tt := range tests {
+		got, err := ParseAddr(tt.text, stubSource{})
+		if err != nil || got != tt.want {
+			t.Errorf("ParseAddr(%q) = $%04X, %v; want $%04X", tt.text, got, err, tt.want)
+		}
+	}
+}
+
+func TestParseAddrErrors(t *testing.T) {
+	for _, tt := range []struct {
+		text string
+		src  AddrSource
+	}{
+		{"", stubSource{}},
+		{"NOSUCHSYMBOL", stubSource{}},
+		{"#70000", stubSource{}},
+		{"$12G", stubSource{}},
+		{"[RUNAD", stubSource{}},
+		{"RUNAD+", stubSource{}},
+		{"0600 0700", stubSource{}},
+		{"PC", nil},
+		{"[RUNAD]", nil},
+	} {
+		if got, err := ParseAddr(tt.text, tt.src); err == nil {
+			t.Errorf("ParseAddr(%q) = $%04X, want error", tt.text, got)
+		}
+	}
+	if got, err := ParseAddr("RUNAD+#2", nil); err != nil || got != 0x02E2 {
+		t.Errorf("ParseAddr without a source = $%04X, %v; want $02E2", got, err)
+	}
+}
@@ -0,0 +1,165 @@
+package memory
+
+import (
+	"fmt"
+	"strings"
+
+	"go800mon/internal/atascii"
+)
+
+// ByteMatch matches a byte b when b&Mask == Value.
+type ByteMatch struct {
+	Value byte
+	Mask  byte
+}
+
+// PatternElement matches one byte against any of its alternatives.
+type PatternElement []ByteMatch
+
+func (e PatternElement) Match(b byte) bool {
+	for _, m := range e {
+		if b&m.Mask == m.Value {
+			return true
+		}
+	}
+	return false
+}
+
+// Pattern is a byte pattern for client-side memory search.
+type Pattern []PatternElement
+
+// ParsePattern reads whitespace-separated terms:
+//
+//	A9       exact byte
+//	??       any byte
+//	A? ?9    nibble masks
+//	(A9|AD)  one of several byte terms, spaces allowed: (A9 | AD)
+//	w:1234   16-bit value, little-endian
+//	"TEXT"   ATASCII text, or screen codes with screenCodes; letters
+//	         match either case with ignoreCase
+func ParsePattern(text string, ignoreCase, screenCodes bool) (Pattern, error) {
+	var out Pattern
+	rest := strings.TrimSpace(text)
+	for rest != "" {
+		var term string
+		if rest[0] == '"' {
+			end := strings.IndexByte(rest[1:], '"')
+			if end < 0 {
+				return nil, fmt.Errorf("Unterminated text in pattern: %s", rest)
+			}
+			term, rest = rest[:end+2], rest[end+2:]
+		} else if rest[0] == '(' {
+			end := strings.IndexByte(rest, ')')
+			if end < 0 {
+				return nil, fmt.Errorf("Unterminated alternatives in pattern: %s", rest)
+			}
+			term, rest = rest[:end+1], rest[end+1:]
+		} else if i := strings.IndexAny(rest, " \t"); i >= 0 {
+			term, rest = rest[:i], rest[i:]
+		} else {
+			term, rest = rest, ""
+		}
+		rest = strings.TrimSpace(rest)
+		elems, err := parsePatternTerm(term, ignoreCase, screenCodes)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, elems...)
+	}
+	if len(out) == 0 {
+		return nil, fmt.Errorf("Pattern is empty.")
+	}
+	return out, nil
+}
+
+func parsePatternTerm(term string, ignoreCase, screenCodes bool) ([]PatternElement, error) {
+	upper := strings.ToUpper(term)
+	switch {
+	case strings.HasPrefix(term, `"`):
+		data, err := atascii.EncodeText(term[1 : len(term)-1])
+		if err != nil {
+			return nil, err
+		}
+		out := make([]PatternElement, len(data))
+		for i, b := range data {
+			alts := []byte{b}
+			if ignoreCase && isATASCIILetter(b) {
+				alts = append(alts, b^0x20)
+			}
+			for _, alt := range alts {
+				if screenCodes {
+					alt = atascii.ATASCIIToScreen(alt)
+				}
+				out[i] = append(out[i], ByteMatch{Value: alt, Mask: 0xFF})
+			}
+		}
+		return out, nil
+	case strings.HasPrefix(upper, "W:"):
+		v, err := ParseHex(term[2:])
+		if err != nil {
+			return nil, fmt.Errorf("Invalid word in pattern: %s", term)
+		}
+		return []PatternElement{{{Value: byte(v), Mask: 0xFF}}, {{Value: byte(v >> 8), Mask: 0xFF}}}, nil
+	case strings.HasPrefix(term, "(") && strings.HasSuffix(term, ")"):
+		var elem PatternElement
+		for _, alt := range strings.Split(term[1:len(term)-1], "|") {
+			m, err := parseByteMatch(alt)
+			if err != nil {
+				return nil, err
+			}
+			elem = append(elem, m)
+		}
+		return []PatternElement{elem}, nil
+	}
+	m, err := parseByteMatch(term)
+	if err != nil {
+		return nil, err
+	}
+	return []PatternElement{{m}}, nil
+}
+
+// parseByteMatch reads two hex digits, either of which may be '?'.
+func parseByteMatch(text string) (ByteMatch, error) {
+	text = strings.TrimPrefix(strings.ToUpper(strings.TrimSpace(text)), "$")
+	if len(text) != 2 {
+		return ByteMatch{}, fmt.Errorf("Invalid byte in pattern: %s", text)
+	}
+	var m ByteMatch
+	for i, shift := range []uint{4, 0} {
+		c := text[i]
+		if c == '?' {
+			continue
+		}
+		v := strings.IndexByte("0123456789ABCDEF", c)
+		if v < 0 {
+			return ByteMatch{}, fmt.Errorf("Invalid byte in pattern: %s", text)
+		}
+		m.Value |= byte(v) << shift
+		m.Mask |= 0x0F << shift
+	}
+	return m, nil
+}
+
+func isATASCIILetter(b byte) bool {
+	l := b &^ 0x20
+	return l >= 'A' && l <= 'Z'
+}
+
+// FindAll returns the address of every match in data, which was read at
+// base. Matches may overlap.
+func (p Pattern) FindAll(base uint16, data []byte) []uint16 {
+	var out []uint16
+	for i := 0; i+len(p) <= len(data); i++ {
+		ok := true
+		for j, e := range p {
+			if !e.Match(data[i+j]) {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			out = append(out, base+uint16(i))
+		}
+	}
+	return out
+}
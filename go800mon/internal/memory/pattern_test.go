@@ -0,0 +1,45 @@
+package memory
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestParsePattern(t *testing.T) {
+	data := []byte{0xA9, 0x00, 0xAD, 0x34, 0x12, 0x8D, 0x41, 0x62, 0x21, 0xA9, 0x3F}
+	tests := []struct {
+		pattern     string
+		ignoreCase  bool
+		screenCodes bool
+		want        []uint16
+	}{
+		{pattern: "A9", want: []uint16{0x1000, 0x1009}},
+		{pattern: "A9 ??", want: []uint16{0x1000, 0x1009}},
+		{pattern: "?D", want: []uint16{0x1002, 0x1005}},
+		{pattern: "A? 3?", want: []uint16{0x1002, 0x1009}},
+		{pattern: "(A9|AD)", want: []uint16{0x1000, 0x1002, 0x1009}},
+		{pattern: "( A9 | AD ) ??", want: []uint16{0x1000, 0x1002, 0x1009}},
+		{pattern: "AD w:1234", want: []uint16{0x1002}},
+		{pattern: `"Ab"`, want: []uint16{0x1006}},
+		{pattern: `"aB"`, ignoreCase: true, want: []uint16{0x1006}},
+		{pattern: `"A"`, screenCodes: true, want: []uint16{0x1008}},
+	}
+	for _, tt := range tests {
+		p, err := ParsePattern(tt.pattern, tt.ignoreCase, tt.screenCodes)
+		if err != nil {
+			t.Errorf("ParsePattern(%q): %v", tt.pattern, err)
+			continue
+		}
+		if got := p.FindAll(0x1000, data); !slices.Equal(got, tt.want) {
+			t.Errorf("%q matches %04X, want %04X", tt.pattern, got, tt.want)
+		}
+	}
+}
+
+func TestParsePatternErrors(t *testing.T) {
+	for _, pattern := range []string{"", "A", "A9G", "GG", "(A9|AD", `"open`, "w:XYZ", "(A9|)"} {
+		if _, err := ParsePattern(pattern, false, false); err == nil {
+			t.Errorf("ParsePattern(%q) succeeded, want error", pattern)
+		}
+	}
+}
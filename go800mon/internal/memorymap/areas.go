@@ -0,0 +1,52 @@
+package memorymap
+
+// AreaKind tells how the machine treats writes to an area.
+type AreaKind int
+
+const (
+	// AreaOS is RAM the OS keeps its state in.
+	AreaOS AreaKind = iota
+	// AreaROM is covered by ROM when the OS, BASIC or a cartridge is
+	// enabled; writes go to the RAM underneath, if any.
+	AreaROM
+	// AreaIO holds hardware registers; writes do not reach RAM.
+	AreaIO
+)
+
+// Area is a named OS, ROM or hardware address range of the XL/XE memory
+// map.
+type Area struct {
+	Start uint16
+	End   uint16
+	Name  string
+	Kind  AreaKind
+}
+
+var Areas = []Area{
+	{0x0000, 0x007F, "OS zero page", AreaOS},
+	{0x0100, 0x01FF, "6502 stack", AreaOS},
+	{0x0200, 0x047F, "OS variables and IOCBs", AreaOS},
+	{0x0580, 0x05FF, "FP and line buffer", AreaOS},
+	{0x5000, 0x57FF, "Self-test ROM", AreaROM},
+	{0xA000, 0xBFFF, "BASIC / cartridge A ROM", AreaROM},
+	{0xC000, 0xCFFF, "OS ROM", AreaROM},
+	{0xD000, 0xD0FF, "GTIA", AreaIO},
+	{0xD100, 0xD1FF, "PBI", AreaIO},
+	{0xD200, 0xD2FF, "POKEY", AreaIO},
+	{0xD300, 0xD3FF, "PIA", AreaIO},
+	{0xD400, 0xD4FF, "ANTIC", AreaIO},
+	{0xD500, 0xD5FF, "Cartridge control", AreaIO},
+	{0xD600, 0xD7FF, "Unused I/O", AreaIO},
+	{0xD800, 0xFFFF, "OS ROM and FP package", AreaROM},
+}
+
+// AreasIn returns the areas overlapping start-end, inclusive.
+func AreasIn(start, end uint16) []Area {
+	var out []Area
+	for _, a := range Areas {
+		if a.Start <= end && start <= a.End {
+			out = append(out, a)
+		}
+	}
+	return out
+}
@@ -13,10 +13,22 @@ func FindByComment(query string) (uint16, bool) {
 	if len(terms) == 0 {
 		return 0, false
 	}
+	for _, each := range symbolLayers {
+		if addr, ok := findInLayer(each, terms); ok {
+			return addr, true
+		}
+	}
+	return 0, false
+}
+
+// findInLayer matches terms against the symbols each lists. Several terms
+// must all appear in the name; a single term prefers an exact name, then a
+// prefix, then a substring. Ties are settled as for LookupName.
+func findInLayer(each func(fn func(addr uint16, name string)), terms []string) (uint16, bool) {
 	if len(terms) > 1 {
 		var addrOut uint16
 		ok := false
-		for addr, name := range symbols {
+		each(func(addr uint16, name string) {
 			s := strings.ToLower(name)
 			match := true
 			for _, term := range terms {
@@ -26,19 +38,19 @@ func FindByComment(query string) (uint16, bool) {
 				}
 			}
 			if !match {
-				continue
+				return
 			}
-			if !ok || addr < addrOut {
+			if !ok || preferredAddr(addr, addrOut) {
 				addrOut = addr
 				ok = true
 			}
-		}
+		})
 		if ok {
 			return addrOut, true
 		}
 		return 0, false
 	}
-	q = terms[0]
+	q := terms[0]
 
 	var exactAddr uint16
 	exactOK := false
@@ -47,29 +59,29 @@ func FindByComment(query string) (uint16, bool) {
 	var containsAddr uint16
 	containsOK := false
 
-	for addr, name := range symbols {
+	each(func(addr uint16, name string) {
 		s := strings.ToLower(name)
 		if s == q {
-			if !exactOK || addr < exactAddr {
+			if !exactOK || preferredAddr(addr, exactAddr) {
 				exactAddr = addr
 				exactOK = true
 			}
-			continue
+			return
 		}
 		if strings.HasPrefix(s, q) {
-			if !prefixOK || addr < prefixAddr {
+			if !prefixOK || preferredAddr(addr, prefixAddr) {
 				prefixAddr = addr
 				prefixOK = true
 			}
-			continue
+			return
 		}
 		if strings.Contains(s, q) {
-			if !containsOK || addr < containsAddr {
+			if !containsOK || preferredAddr(addr, containsAddr) {
 				containsAddr = addr
 				containsOK = true
 			}
 		}
-	}
+	})
 
 	if exactOK {
 		return exactAddr, true
@@ -831,10 +831,3 @@ var symbols = map[uint16]string{
 	0xE80E: "IRQST",
 	0xE80F: "SKSTAT",
 }
-
-func Lookup(addr uint16) string {
-	if v, ok := symbols[addr]; ok {
-		return v
-	}
-	return ""
-}
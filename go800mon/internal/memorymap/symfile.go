@@ -0,0 +1,237 @@
+package memorymap
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Program symbols loaded from assembler label files. They take precedence
+// over the built-in OS and hardware names.
+var (
+	userMu      sync.RWMutex
+	userSymbols map[uint16]string
+	userNames   map[string]uint16
+	userFiles   []string
+)
+
+// LoadSymbolFiles replaces the program symbol layer with labels read from
+// paths. MADS and xasm .lab, ca65/ld65 .lbl (VICE) and .dbg files are
+// recognised by content. It returns the number of labels loaded.
+func LoadSymbolFiles(paths []string) (int, error) {
+	byAddr := map[uint16]string{}
+	byName := map[string]uint16{}
+	for _, path := range paths {
+		if err := readSymbolFile(path, byAddr, byName); err != nil {
+			return 0, err
+		}
+	}
+	userMu.Lock()
+	defer userMu.Unlock()
+	userSymbols = byAddr
+	userNames = byName
+	userFiles = append([]string(nil), paths...)
+	return len(byName), nil
+}
+
+// ReloadSymbolFiles re-reads the files passed to the last LoadSymbolFiles call.
+func ReloadSymbolFiles() (int, error) {
+	userMu.RLock()
+	paths := append([]string(nil), userFiles...)
+	userMu.RUnlock()
+	return LoadSymbolFiles(paths)
+}
+
+// LookupName resolves a symbol name (case-insensitive) to its address.
+func LookupName(name string) (uint16, bool) {
+	key := strings.ToUpper(strings.TrimSpace(name))
+	if key == "" {
+		return 0, false
+	}
+	userMu.RLock()
+	addr, ok := userNames[key]
+	userMu.RUnlock()
+	if ok {
+		return addr, true
+	}
+	addr, ok = builtinNames[key]
+	return addr, ok
+}
+
+// builtinNames indexes the built-in symbols by upper-case name.
+var builtinNames = buildBuiltinNames()
+
+// buildBuiltinNames maps every built-in name to one address. Where a name
+// is defined twice, the OS variable or 400/800/XL register wins over the
+// BASIC zero-page copy at $80-$CA and the 5200 GTIA and POKEY registers at
+// $C0xx and $E8xx; a remaining tie resolves to the lowest address.
+func buildBuiltinNames() map[string]uint16 {
+	out := make(map[string]uint16, len(symbols))
+	for addr, name := range symbols {
+		key := strings.ToUpper(name)
+		if prev, exists := out[key]; exists && !preferredAddr(addr, prev) {
+			continue
+		}
+		out[key] = addr
+	}
+	return out
+}
+
+// preferredAddr reports whether a symbol at addr ranks before one with the
+// same name at prev.
+func preferredAddr(addr, prev uint16) bool {
+	shadowed := func(addr uint16) bool {
+		return addr >= 0x0080 && addr <= 0x00CA || addr&0xFF00 == 0xC000 || addr&0xFF00 == 0xE800
+	}
+	if shadowed(addr) != shadowed(prev) {
+		return !shadowed(addr)
+	}
+	return addr < prev
+}
+
+// Lookup returns the symbol for addr, preferring program labels.
+func Lookup(addr uint16) string {
+	userMu.RLock()
+	name, ok := userSymbols[addr]
+	userMu.RUnlock()
+	if ok {
+		return name
+	}
+	return symbols[addr]
+}
+
+// Nearest returns the closest symbol at or below addr, up to maxDist bytes
+// away, with the distance.
+func Nearest(addr uint16, maxDist int) (string, int, bool) {
+	for d := 0; d <= maxDist && d <= int(addr); d++ {
+		if name := Lookup(addr - uint16(d)); name != "" {
+			return name, d, true
+		}
+	}
+	return "", 0, false
+}
+
+// symbolLayers lists the program symbols before the built-in ones. A search
+// settles in the first layer that has a match.
+var symbolLayers = []func(fn func(addr uint16, name string)){eachUserSymbol, eachBuiltinSymbol}
+
+func eachUserSymbol(fn func(addr uint16, name string)) {
+	userMu.RLock()
+	defer userMu.RUnlock()
+	for name, addr := range userNames {
+		fn(addr, name)
+	}
+}
+
+func eachBuiltinSymbol(fn func(addr uint16, name string)) {
+	for addr, name := range symbols {
+		fn(addr, name)
+	}
+}
+
+func readSymbolFile(path string, byAddr map[uint16]string, byName map[string]uint16) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		name, addr, ok := parseSymbolLine(scanner.Text())
+		if !ok {
+			continue
+		}
+		key := strings.ToUpper(name)
+		if _, exists := byName[key]; !exists {
+			byName[key] = addr
+		}
+		if _, exists := byAddr[addr]; !exists {
+			byAddr[addr] = name
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	return nil
+}
+
+// parseSymbolLine recognises:
+//
+//	MADS:      00	2000	START
+//	xasm:      n 2000 START
+//	ca65 .lbl: al 002000 .start
+//	ca65 .dbg: sym	id=0,name="start",...,val=0x2000,...,type=lab
+func parseSymbolLine(line string) (string, uint16, bool) {
+	if strings.HasPrefix(line, "sym\t") {
+		return parseDbgSymbol(line[4:])
+	}
+	fields := strings.Fields(line)
+	if len(fields) == 3 && fields[0] == "al" {
+		addr, ok := parseSymbolHex(fields[1])
+		return strings.TrimPrefix(fields[2], "."), addr, ok && fields[2] != "."
+	}
+	if len(fields) < 2 {
+		return "", 0, false
+	}
+	name := fields[len(fields)-1]
+	addr, ok := parseSymbolHex(fields[len(fields)-2])
+	if !ok || !isSymbolName(name) {
+		return "", 0, false
+	}
+	return name, addr, true
+}
+
+func parseDbgSymbol(attrs string) (string, uint16, bool) {
+	name := ""
+	val := ""
+	typ := ""
+	for _, kv := range strings.Split(attrs, ",") {
+		key, value, ok := strings.Cut(kv, "=")
+		if !ok {
+			continue
+		}
+		switch key {
+		case "name":
+			name = strings.Trim(value, "\"")
+		case "val":
+			val = value
+		case "type":
+			typ = value
+		}
+	}
+	if typ != "lab" || name == "" {
+		return "", 0, false
+	}
+	n, err := strconv.ParseUint(strings.TrimPrefix(val, "0x"), 16, 32)
+	if err != nil || n > 0xFFFF {
+		return "", 0, false
+	}
+	return name, uint16(n), true
+}
+
+func parseSymbolHex(text string) (uint16, bool) {
+	if len(text) > 6 {
+		return 0, false
+	}
+	n, err := strconv.ParseUint(text, 16, 32)
+	if err != nil || n > 0xFFFF {
+		return 0, false
+	}
+	return uint16(n), true
+}
+
+func isSymbolName(name string) bool {
+	for i, r := range name {
+		switch {
+		case r == '_' || r == '@' || r == '?' || (r >= 'A' && r <= 'Z') || (r >= 'a' && r <= 'z'):
+		case i > 0 && (r == '.' || (r >= '0' && r <= '9')):
+		default:
+			return false
+		}
+	}
+	return name != ""
+}
@@ -0,0 +1,113 @@
+package memorymap
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseSymbolLine(t *testing.T) {
+	tests := []struct {
+		line string
+		name string
+		addr uint16
+		ok   bool
+	}{
+		{line: "00\t2000\tSTART", name: "START", addr: 0x2000, ok: true},
+		{line: "n 3C00 PLAYER.X", name: "PLAYER.X", addr: 0x3C00, ok: true},
+		{line: "al 002000 .start", name: "start", addr: 0x2000, ok: true},
+		{line: "sym\tid=0,name=\"loop\",addrsize=absolute,scope=0,def=1,val=0x2010,type=lab", name: "loop", addr: 0x2010, ok: true},
+		{line: "sym\tid=1,name=\"SIZE\",addrsize=zeropage,scope=0,def=2,val=0x10,type=equ", ok: false},
+		{line: "mads 2.1.0", ok: false},
+		{line: "Label table:", ok: false},
+		{line: "00\t12345\tTOOBIG", ok: false},
+		{line: "00\t2000\t1ABC", ok: false},
+	}
+	for _, tt := range tests {
+		name, addr, ok := parseSymbolLine(tt.line)
+		if ok != tt.ok || ok && (name != tt.name || addr != tt.addr) {
+			t.Errorf("parseSymbolLine(%q) = %q, $%04X, %t; want %q, $%04X, %t", tt.line, name, addr, ok, tt.name, tt.addr, tt.ok)
+		}
+	}
+}
+
+func TestLookupNameBuiltinDuplicates(t *testing.T) {
+	tests := map[string]uint16{
+		"COLBK":  0xD01A,
+		"colbk":  0xD01A,
+		"RANDOM": 0xD20A,
+		"MEMTOP": 0x02E5,
+		"WSYNC":  0xD40A,
+	}
+	for name, want := range tests {
+		// Repeat to catch map iteration order leaking into the result.
+		for i := 0; i < 20; i++ {
+			if got, ok := LookupName(name); !ok || got != want {
+				t.Fatalf("LookupName(%q) = $%04X, %t; want $%04X", name, got, ok, want)
+			}
+		}
+	}
+}
+
+func TestLoadSymbolFiles(t *testing.T) {
+	t.Cleanup(func() { _, _ = LoadSymbolFiles(nil) })
+	path := filepath.Join(t.TempDir(), "game.lab")
+	data := "mads 2.1.0\nLabel table:\n00\t2000\tSTART\n00\tD01A\tBORDER\n00\t2000\tMAIN\n"
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	n, err := LoadSymbolFiles([]string{path})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 3 {
+		t.Fatalf("loaded %d labels, want 3", n)
+	}
+	if addr, ok := LookupName("start"); !ok || addr != 0x2000 {
+		t.Fatalf("LookupName(start) = $%04X, %t", addr, ok)
+	}
+	if got := Lookup(0x2000); got != "START" {
+		t.Fatalf("Lookup($2000) = %q, want the first label START", got)
+	}
+	if got := Lookup(0xD01A); got != "BORDER" {
+		t.Fatalf("Lookup($D01A) = %q, want the program label to win", got)
+	}
+	if err := os.WriteFile(path, []byte("00\t3000\tSTART\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if n, err := ReloadSymbolFiles(); err != nil || n != 1 {
+		t.Fatalf("ReloadSymbolFiles() = %d, %v", n, err)
+	}
+	if addr, _ := LookupName("START"); addr != 0x3000 {
+		t.Fatalf("LookupName(START) after reload = $%04X, want $3000", addr)
+	}
+	if _, err := LoadSymbolFiles([]string{filepath.Join(t.TempDir(), "missing.lab")}); err == nil {
+		t.Fatal("LoadSymbolFiles with a missing file succeeded")
+	}
+	if addr, _ := LookupName("START"); addr != 0x3000 {
+		t.Fatal("failed load replaced the loaded symbols")
+	}
+}
+
+func TestFindByCommentPrefersProgramLabels(t *testing.T) {
+	t.Cleanup(func() { _, _ = LoadSymbolFiles(nil) })
+	path := filepath.Join(t.TempDir(), "game.lab")
+	if err := os.WriteFile(path, []byte("00\t3000\tCH\n00\t3100\tCHBASE_COPY\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadSymbolFiles([]string{path}); err != nil {
+		t.Fatal(err)
+	}
+	tests := map[string]uint16{
+		"CH":        0x3000,
+		"chbase_":   0x3100,
+		"ase_co":    0x3100,
+		"base copy": 0x3100,
+		"memtop":    0x02E5,
+	}
+	for query, want := range tests {
+		if got, ok := FindByComment(query); !ok || got != want {
+			t.Errorf("FindByComment(%q) = $%04X, %t; want $%04X", query, got, ok, want)
+		}
+	}
+}
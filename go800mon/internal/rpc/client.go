@@ -61,6 +61,15 @@ const (
 	CmdSetReg          Command = 42
 )
 
+// MaxPayload is the largest request or response payload accepted by the
+// Remote Monitor.
+const MaxPayload = 4096
+
+type Range struct {
+	Addr   uint16
+	Length int
+}
+
 type Status struct {
 	Paused      bool
 	EmuMS       uint64
@@ -470,16 +479,24 @@ func (c *Client) ReadMemory(ctx context.Context, addr, length uint16) ([]byte, e
 	return c.Call(ctx, CmdMemRead, buf)
 }
 
+// WriteMemory writes data at addr, split into as many WRITE_MEMORY requests
+// as the payload limit requires.
 func (c *Client) WriteMemory(ctx context.Context, addr uint16, data []byte) error {
-	if len(data) > 0xFFFF {
-		return fmt.Errorf("write_memory payload too long: %d bytes (max 65535)", len(data))
-	}
-	payload := make([]byte, 4+len(data))
-	binary.LittleEndian.PutUint16(payload[0:2], addr)
-	binary.LittleEndian.PutUint16(payload[2:4], uint16(len(data)))
-	copy(payload[4:], data)
-	_, err := c.Call(ctx, CmdWriteMemory, payload)
-	return err
+	if len(data) > 0x10000 {
+		return fmt.Errorf("write_memory payload too long: %d bytes (max 65536)", len(data))
+	}
+	const maxChunk = MaxPayload - 4
+	for off := 0; off == 0 || off < len(data); off += maxChunk {
+		chunk := data[off:min(off+maxChunk, len(data))]
+		payload := make([]byte, 4+len(chunk))
+		binary.LittleEndian.PutUint16(payload[0:2], addr+uint16(off))
+		binary.LittleEndian.PutUint16(payload[2:4], uint16(len(chunk)))
+		copy(payload[4:], chunk)
+		if _, err := c.Call(ctx, CmdWriteMemory, payload); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 func (c *Client) ReadMemoryChunked(ctx context.Context, addr uint16, length int, maxChunk int) ([]byte, error) {
@@ -511,6 +528,73 @@ func (c *Client) ReadMemoryChunked(ctx context.Context, addr uint16, length int,
 	return res, nil
 }
 
+// ReadMemoryV reads several ranges with READ_MEMV, packing as many
+// descriptors per request as the payload limit allows. Ranges longer than a
+// single response are split transparently; results follow the input order.
+func (c *Client) ReadMemoryV(ctx context.Context, ranges []Range) ([][]byte, error) {
+	type piece struct {
+		idx    int
+		addr   uint16
+		length int
+	}
+	out := make([][]byte, len(ranges))
+	batch := make([]piece, 0, 64)
+	total := 0
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		payload := make([]byte, 2+len(batch)*4)
+		binary.LittleEndian.PutUint16(payload[0:2], uint16(len(batch)))
+		for i, p := range batch {
+			off := 2 + i*4
+			binary.LittleEndian.PutUint16(payload[off:off+2], p.addr)
+			binary.LittleEndian.PutUint16(payload[off+2:off+4], uint16(p.length))
+		}
+		data, err := c.Call(ctx, CmdMemReadV, payload)
+		if err != nil {
+			return err
+		}
+		if len(data) < total {
+			return fmt.Errorf("READ_MEMV payload too short: got=%d expected=%d", len(data), total)
+		}
+		off := 0
+		for _, p := range batch {
+			out[p.idx] = append(out[p.idx], data[off:off+p.length]...)
+			off += p.length
+		}
+		batch = batch[:0]
+		total = 0
+		return nil
+	}
+	for idx, r := range ranges {
+		if r.Length > 0 {
+			out[idx] = make([]byte, 0, r.Length)
+		}
+		addr := r.Addr
+		remaining := r.Length
+		for remaining > 0 {
+			if total == MaxPayload || 2+(len(batch)+1)*4 > MaxPayload {
+				if err := flush(); err != nil {
+					return nil, err
+				}
+			}
+			take := remaining
+			if take > MaxPayload-total {
+				take = MaxPayload - total
+			}
+			batch = append(batch, piece{idx: idx, addr: addr, length: take})
+			total += take
+			addr = uint16((int(addr) + take) & 0xFFFF)
+			remaining -= take
+		}
+	}
+	if err := flush(); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *Client) ReadDisplayList(ctx context.Context) ([]byte, error) {
 	return c.Call(ctx, CmdDlistDump, nil)
 }
@@ -868,6 +952,23 @@ func (c *Client) BPSetEnabled(ctx context.Context, enabled bool) (bool, error) {
 	return data[0] != 0, nil
 }
 
+// BLine returns the scanline break setting and its mode (0 off, 1 break,
+// 2 blink), setting it to *line first when line is not nil.
+func (c *Client) BLine(ctx context.Context, line *uint16) (uint16, byte, error) {
+	var payload []byte
+	if line != nil {
+		payload = binary.LittleEndian.AppendUint16(nil, *line)
+	}
+	data, err := c.Call(ctx, CmdBLine, payload)
+	if err != nil {
+		return 0, 0, err
+	}
+	if len(data) < 3 {
+		return 0, 0, errors.New("bline payload too short")
+	}
+	return binary.LittleEndian.Uint16(data[0:2]), data[2], nil
+}
+
 func (c *Client) BPList(ctx context.Context) (BreakpointList, error) {
 	data, err := c.Call(ctx, CmdBPList, nil)
 	if err != nil {
@@ -0,0 +1,52 @@
+package rpc_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"go800mon/internal/rpc"
+	"go800mon/internal/rpc/rpctest"
+)
+
+func TestReadMemoryV(t *testing.T) {
+	srv, err := rpctest.NewServer("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = srv.Close() })
+	mem := make([]byte, 0x10000)
+	for i := range mem {
+		mem[i] = byte(i ^ i>>8)
+	}
+	srv.LoadMemory(0, mem)
+	ranges := []rpc.Range{
+		{Addr: 0x1000, Length: 3000},
+		{Addr: 0x0010, Length: 2000},
+		// Longer than one response, so it is split across requests.
+		{Addr: 0x8000, Length: 0x3000},
+		{Addr: 0x4000, Length: 0},
+		{Addr: 0xFFF6, Length: 10},
+	}
+	cl := rpc.New(srv.Path())
+	defer cl.Close()
+	got, err := cl.ReadMemoryV(context.Background(), ranges)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != len(ranges) {
+		t.Fatalf("got %d results, want %d", len(got), len(ranges))
+	}
+	total := 0
+	for i, r := range ranges {
+		want := mem[r.Addr : int(r.Addr)+r.Length]
+		if !bytes.Equal(got[i], want) {
+			t.Errorf("range %d ($%04X+%d): got %d bytes, not the memory there", i, r.Addr, r.Length, len(got[i]))
+		}
+		total += r.Length
+	}
+	// Ranges are packed into as few full payloads as possible.
+	if calls, want := srv.Calls(rpc.CmdMemReadV), (total+rpc.MaxPayload-1)/rpc.MaxPayload; calls != want {
+		t.Errorf("READ_MEMV sent %d times, want %d", calls, want)
+	}
+}
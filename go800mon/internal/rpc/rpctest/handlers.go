@@ -0,0 +1,835 @@
+package rpctest
+
+import (
+	"encoding/binary"
+	"os"
+	"strings"
+
+	"go800mon/internal/atascii"
+	"go800mon/internal/disasm"
+	"go800mon/internal/rpc"
+)
+
+type handler func(st *State, payload []byte) ([]byte, error)
+
+var handlers = map[rpc.Command]handler{
+	rpc.CmdPing:            handlePing,
+	rpc.CmdDlistAddr:       handleDlistAddr,
+	rpc.CmdMemRead:         handleMemRead,
+	rpc.CmdDlistDump:       handleDlistDump,
+	rpc.CmdCPUState:        handleCPUState,
+	rpc.CmdPause:           handlePause,
+	rpc.CmdContinue:        handleContinue,
+	rpc.CmdStep:            handleStep,
+	rpc.CmdStepVBlank:      handleStepVBlank,
+	rpc.CmdStatus:          handleStatus,
+	rpc.CmdMemReadV:        handleMemReadV,
+	rpc.CmdRun:             handleRun,
+	rpc.CmdColdstart:       handleColdstart,
+	rpc.CmdWarmstart:       handleWarmstart,
+	rpc.CmdRemoveCartrige:  handleRemoveCartrige,
+	rpc.CmdStopEmulator:    handleStopEmulator,
+	rpc.CmdRemoveTape:      handleRemoveTape,
+	rpc.CmdRemoveDisks:     handleRemoveDisks,
+	rpc.CmdHistory:         handleHistory,
+	rpc.CmdBuiltinMonitor:  handleBuiltinMonitor,
+	rpc.CmdWriteMemory:     handleWriteMemory,
+	rpc.CmdBPClear:         handleBPClear,
+	rpc.CmdBPAddClause:     handleBPAddClause,
+	rpc.CmdBPDeleteClause:  handleBPDeleteClause,
+	rpc.CmdBPSetEnabled:    handleBPSetEnabled,
+	rpc.CmdBPList:          handleBPList,
+	rpc.CmdBuildFeatures:   handleBuildFeatures,
+	rpc.CmdRestartEmulator: handleRestartEmulator,
+	rpc.CmdGTIAState:       handleGTIAState,
+	rpc.CmdANTICState:      handleANTICState,
+	rpc.CmdCartState:       handleCartState,
+	rpc.CmdJumps:           handleJumps,
+	rpc.CmdPIAState:        handlePIAState,
+	rpc.CmdPOKEYState:      handlePOKEYState,
+	rpc.CmdStack:           handleStack,
+	rpc.CmdStepOver:        handleStepOver,
+	rpc.CmdRunUntilReturn:  handleRunUntilReturn,
+	rpc.CmdBBRK:            handleBBRK,
+	rpc.CmdBLine:           handleBLine,
+	rpc.CmdSysinfo:         handleSysinfo,
+	rpc.CmdSearch:          handleSearch,
+	rpc.CmdSetReg:          handleSetReg,
+}
+
+const (
+	historySteps = 32
+	jumpSteps    = 16
+	frameMS      = 20
+)
+
+func expectEmpty(payload []byte) error {
+	if len(payload) != 0 {
+		_, err := fail(StatusInvalidLength, "payload must be empty")
+		return err
+	}
+	return nil
+}
+
+func u16(v uint16) []byte {
+	out := make([]byte, 2)
+	binary.LittleEndian.PutUint16(out, v)
+	return out
+}
+
+func (st *State) word(addr uint16) uint16 {
+	return uint16(st.Memory[addr]) | uint16(st.Memory[addr+1])<<8
+}
+
+func (st *State) read(addr uint16, n int) []byte {
+	out := make([]byte, n)
+	for i := range out {
+		out[i] = st.Memory[uint16(int(addr)+i)]
+	}
+	return out
+}
+
+func handlePing(st *State, payload []byte) ([]byte, error) {
+	if err := expectEmpty(payload); err != nil {
+		return nil, err
+	}
+	return []byte("PONG"), nil
+}
+
+func handleDlistAddr(st *State, payload []byte) ([]byte, error) {
+	if err := expectEmpty(payload); err != nil {
+		return nil, err
+	}
+	return u16(st.ANTIC.DLIST), nil
+}
+
+func handleMemRead(st *State, payload []byte) ([]byte, error) {
+	if len(payload) != 4 {
+		return fail(StatusInvalidLength, "READ_MEM expects addr and count")
+	}
+	addr := binary.LittleEndian.Uint16(payload[0:2])
+	count := int(binary.LittleEndian.Uint16(payload[2:4]))
+	if count > rpc.MaxPayload {
+		return fail(StatusPayloadTooLarge, "READ_MEM count exceeds payload limit")
+	}
+	return st.read(addr, count), nil
+}
+
+func handleMemReadV(st *State, payload []byte) ([]byte, error) {
+	if len(payload) < 2 {
+		return fail(StatusInvalidLength, "READ_MEMV header missing")
+	}
+	count := int(binary.LittleEndian.Uint16(payload[0:2]))
+	if len(payload) != 2+count*4 {
+		return fail(StatusInvalidLength, "READ_MEMV descriptor count mismatch")
+	}
+	out := make([]byte, 0, rpc.MaxPayload)
+	for i := 0; i < count; i++ {
+		off := 2 + i*4
+		addr := binary.LittleEndian.Uint16(payload[off : off+2])
+		ln := int(binary.LittleEndian.Uint16(payload[off+2 : off+4]))
+		if len(out)+ln > rpc.MaxPayload {
+			return fail(StatusPayloadTooLarge, "READ_MEMV total exceeds payload limit")
+		}
+		out = append(out, st.read(addr, ln)...)
+	}
+	return out, nil
+}
+
+func handleDlistDump(st *State, payload []byte) ([]byte, error) {
+	start := st.ANTIC.DLIST
+	switch len(payload) {
+	case 0:
+	case 2:
+		start = binary.LittleEndian.Uint16(payload)
+	default:
+		return fail(StatusInvalidLength, "DLIST_DUMP expects empty or u16 payload")
+	}
+	out := make([]byte, 0, 256)
+	addr := start
+	for len(out) < 1024 {
+		ir := st.Memory[addr]
+		out = append(out, ir)
+		addr++
+		mode := ir & 0x0F
+		if mode == 1 || (mode != 0 && ir&0x40 != 0) {
+			out = append(out, st.Memory[addr], st.Memory[addr+1])
+			addr += 2
+		}
+		if mode == 1 && ir&0x40 != 0 {
+			break
+		}
+	}
+	return out, nil
+}
+
+func handleCPUState(st *State, payload []byte) ([]byte, error) {
+	if err := expectEmpty(payload); err != nil {
+		return nil, err
+	}
+	out := make([]byte, 11)
+	binary.LittleEndian.PutUint16(out[0:2], st.CPU.YPos)
+	binary.LittleEndian.PutUint16(out[2:4], st.CPU.XPos)
+	binary.LittleEndian.PutUint16(out[4:6], st.CPU.PC)
+	out[6] = st.CPU.A
+	out[7] = st.CPU.X
+	out[8] = st.CPU.Y
+	out[9] = st.CPU.S
+	out[10] = st.CPU.P
+	return out, nil
+}
+
+func handlePause(st *State, payload []byte) ([]byte, error) {
+	if err := expectEmpty(payload); err != nil {
+		return nil, err
+	}
+	st.Status.Paused = true
+	return nil, nil
+}
+
+func handleContinue(st *State, payload []byte) ([]byte, error) {
+	if err := expectEmpty(payload); err != nil {
+		return nil, err
+	}
+	st.Status.Paused = false
+	return nil, nil
+}
+
+func handleStep(st *State, payload []byte) ([]byte, error) {
+	if err := expectEmpty(payload); err != nil {
+		return nil, err
+	}
+	st.Status.Paused = true
+	st.step(false)
+	return nil, nil
+}
+
+func handleStepVBlank(st *State, payload []byte) ([]byte, error) {
+	if err := expectEmpty(payload); err != nil {
+		return nil, err
+	}
+	st.Status.Paused = true
+	st.Status.EmuMS += frameMS
+	st.Status.ResetMS += frameMS
+	st.touch()
+	return nil, nil
+}
+
+func handleStatus(st *State, payload []byte) ([]byte, error) {
+	if err := expectEmpty(payload); err != nil {
+		return nil, err
+	}
+	out := make([]byte, 22)
+	if st.Status.Paused {
+		out[0] |= 0x01
+	}
+	if st.Status.Crashed {
+		out[0] |= 0x80
+	}
+	binary.LittleEndian.PutUint64(out[1:9], st.Status.EmuMS)
+	binary.LittleEndian.PutUint64(out[9:17], st.Status.ResetMS)
+	binary.LittleEndian.PutUint32(out[17:21], uint32(st.Status.StateSeq))
+	out[21] = st.Status.MachineType
+	return out, nil
+}
+
+func handleRun(st *State, payload []byte) ([]byte, error) {
+	if len(payload) == 0 {
+		return fail(StatusInvalidLength, "path is empty")
+	}
+	path := strings.TrimRight(string(payload), " \x00")
+	path = strings.Trim(path, "\"")
+	if path == "" {
+		return fail(StatusInvalidValue, "path is empty")
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return fail(StatusFileNotFound, "file not found")
+		}
+		return fail(StatusFileOpenFailed, err.Error())
+	}
+	if info.IsDir() {
+		return fail(StatusUnsupportedFile, "unsupported file type")
+	}
+	st.LastRun = path
+	st.touch()
+	return nil, nil
+}
+
+func handleColdstart(st *State, payload []byte) ([]byte, error) {
+	if err := expectEmpty(payload); err != nil {
+		return nil, err
+	}
+	st.Status.ResetMS = 0
+	st.CPU.PC = st.word(0xFFFC)
+	st.touch()
+	return nil, nil
+}
+
+func handleWarmstart(st *State, payload []byte) ([]byte, error) {
+	if err := expectEmpty(payload); err != nil {
+		return nil, err
+	}
+	st.Status.ResetMS = 0
+	st.touch()
+	return nil, nil
+}
+
+func handleRemoveCartrige(st *State, payload []byte) ([]byte, error) {
+	if err := expectEmpty(payload); err != nil {
+		return nil, err
+	}
+	st.Cart.Main = rpc.CartSlotState{}
+	st.Cart.Piggy = rpc.CartSlotState{}
+	st.touch()
+	return nil, nil
+}
+
+func handleStopEmulator(st *State, payload []byte) ([]byte, error) {
+	if err := expectEmpty(payload); err != nil {
+		return nil, err
+	}
+	st.Stopped = true
+	return nil, nil
+}
+
+func handleRestartEmulator(st *State, payload []byte) ([]byte, error) {
+	if err := expectEmpty(payload); err != nil {
+		return nil, err
+	}
+	st.Restarted = true
+	st.touch()
+	return nil, nil
+}
+
+func handleRemoveTape(st *State, payload []byte) ([]byte, error) {
+	if err := expectEmpty(payload); err != nil {
+		return nil, err
+	}
+	st.TapeMounted = false
+	st.touch()
+	return nil, nil
+}
+
+func handleRemoveDisks(st *State, payload []byte) ([]byte, error) {
+	switch len(payload) {
+	case 0:
+		for i := range st.DisksMounted {
+			st.DisksMounted[i] = false
+		}
+	case 1:
+		n := int(payload[0])
+		if n < 1 || n > len(st.DisksMounted) {
+			return fail(StatusInvalidValue, "invalid disk number")
+		}
+		st.DisksMounted[n-1] = false
+	default:
+		return fail(StatusInvalidLength, "REMOVE_DISKS expects empty or u8 payload")
+	}
+	st.touch()
+	return nil, nil
+}
+
+func handleHistory(st *State, payload []byte) ([]byte, error) {
+	if err := expectEmpty(payload); err != nil {
+		return nil, err
+	}
+	out := make([]byte, 1, 1+len(st.History)*7)
+	out[0] = byte(len(st.History))
+	for _, e := range st.History {
+		out = append(out, e.Y, e.X, byte(e.PC), byte(e.PC>>8), e.Op0, e.Op1, e.Op2)
+	}
+	return out, nil
+}
+
+func handleBuiltinMonitor(st *State, payload []byte) ([]byte, error) {
+	switch len(payload) {
+	case 0:
+		st.BuiltinMonitor = true
+	case 1:
+		if payload[0] > 1 {
+			return fail(StatusInvalidValue, "enabled must be 0 or 1")
+		}
+		st.BuiltinMonitor = payload[0] == 1
+	default:
+		return fail(StatusInvalidLength, "BUILTIN_MONITOR expects empty or u8 payload")
+	}
+	return []byte{boolByte(st.BuiltinMonitor)}, nil
+}
+
+func handleWriteMemory(st *State, payload []byte) ([]byte, error) {
+	if len(payload) < 4 {
+		return fail(StatusInvalidLength, "WRITE_MEMORY header missing")
+	}
+	addr := binary.LittleEndian.Uint16(payload[0:2])
+	ln := int(binary.LittleEndian.Uint16(payload[2:4]))
+	if len(payload) != 4+ln {
+		return fail(StatusInvalidLength, "WRITE_MEMORY length mismatch")
+	}
+	for i, b := range payload[4:] {
+		st.Memory[uint16(int(addr)+i)] = b
+	}
+	st.touch()
+	return nil, nil
+}
+
+func handleBPClear(st *State, payload []byte) ([]byte, error) {
+	if err := expectEmpty(payload); err != nil {
+		return nil, err
+	}
+	st.Breakpoints.Clauses = nil
+	st.touch()
+	return nil, nil
+}
+
+func handleBPAddClause(st *State, payload []byte) ([]byte, error) {
+	if len(payload) < 4 {
+		return fail(StatusInvalidLength, "BP_ADD_CLAUSE header missing")
+	}
+	index := int(binary.LittleEndian.Uint16(payload[0:2]))
+	count := int(payload[2])
+	if count < 1 || count > 20 || payload[3] != 0 {
+		return fail(StatusInvalidValue, "invalid condition count")
+	}
+	if len(payload) != 4+count*6 {
+		return fail(StatusInvalidLength, "BP_ADD_CLAUSE length mismatch")
+	}
+	clause := make([]rpc.BreakpointCondition, 0, count)
+	for i := 0; i < count; i++ {
+		off := 4 + i*6
+		cond := rpc.BreakpointCondition{
+			Type:  payload[off],
+			Op:    payload[off+1],
+			Addr:  binary.LittleEndian.Uint16(payload[off+2 : off+4]),
+			Value: binary.LittleEndian.Uint16(payload[off+4 : off+6]),
+		}
+		if cond.Type < 1 || cond.Type > 9 || cond.Op < 1 || cond.Op > 6 {
+			return fail(StatusInvalidValue, "invalid condition")
+		}
+		clause = append(clause, cond)
+	}
+	clauses := st.Breakpoints.Clauses
+	if index == 0xFFFF || index >= len(clauses) {
+		index = len(clauses)
+	}
+	clauses = append(clauses, nil)
+	copy(clauses[index+1:], clauses[index:])
+	clauses[index] = clause
+	st.Breakpoints.Clauses = clauses
+	st.touch()
+	return u16(uint16(index)), nil
+}
+
+func handleBPDeleteClause(st *State, payload []byte) ([]byte, error) {
+	if len(payload) != 2 {
+		return fail(StatusInvalidLength, "BP_DELETE_CLAUSE expects u16 index")
+	}
+	index := int(binary.LittleEndian.Uint16(payload))
+	if index >= len(st.Breakpoints.Clauses) {
+		return fail(StatusInvalidValue, "clause index out of range")
+	}
+	clauses := st.Breakpoints.Clauses
+	st.Breakpoints.Clauses = append(clauses[:index:index], clauses[index+1:]...)
+	st.touch()
+	return nil, nil
+}
+
+func handleBPSetEnabled(st *State, payload []byte) ([]byte, error) {
+	if len(payload) != 1 {
+		return fail(StatusInvalidLength, "BP_SET_ENABLED expects u8 payload")
+	}
+	st.Breakpoints.Enabled = payload[0] != 0
+	st.touch()
+	return []byte{boolByte(st.Breakpoints.Enabled)}, nil
+}
+
+func handleBPList(st *State, payload []byte) ([]byte, error) {
+	if err := expectEmpty(payload); err != nil {
+		return nil, err
+	}
+	out := []byte{boolByte(st.Breakpoints.Enabled)}
+	out = append(out, u16(uint16(len(st.Breakpoints.Clauses)))...)
+	for _, clause := range st.Breakpoints.Clauses {
+		out = append(out, byte(len(clause)), 0)
+		for _, cond := range clause {
+			out = append(out, cond.Type, cond.Op)
+			out = append(out, u16(cond.Addr)...)
+			out = append(out, u16(cond.Value)...)
+		}
+	}
+	return out, nil
+}
+
+func handleBuildFeatures(st *State, payload []byte) ([]byte, error) {
+	if err := expectEmpty(payload); err != nil {
+		return nil, err
+	}
+	out := u16(uint16(len(st.BuildFeatures)))
+	for _, id := range st.BuildFeatures {
+		out = append(out, u16(id)...)
+	}
+	return out, nil
+}
+
+func handleGTIAState(st *State, payload []byte) ([]byte, error) {
+	if err := expectEmpty(payload); err != nil {
+		return nil, err
+	}
+	g := st.GTIA
+	out := make([]byte, 0, 34)
+	out = append(out, g.HPOSP[:]...)
+	out = append(out, g.HPOSM[:]...)
+	out = append(out, g.SIZEP[:]...)
+	out = append(out, g.SIZEM)
+	out = append(out, g.GRAFP[:]...)
+	out = append(out, g.GRAFM)
+	out = append(out, g.COLPM[:]...)
+	out = append(out, g.COLPF[:]...)
+	out = append(out, g.COLBK, g.PRIOR, g.VDELAY, g.GRACTL)
+	return out, nil
+}
+
+func handleANTICState(st *State, payload []byte) ([]byte, error) {
+	if err := expectEmpty(payload); err != nil {
+		return nil, err
+	}
+	a := st.ANTIC
+	out := []byte{a.DMACTL, a.CHACTL}
+	out = append(out, u16(a.DLIST)...)
+	out = append(out, a.HSCROL, a.VSCROL, a.PMBASE, a.CHBASE, a.VCOUNT, a.NMIEN)
+	out = append(out, u16(a.YPOS)...)
+	return out, nil
+}
+
+func encodeCartSlot(slot rpc.CartSlotState) []byte {
+	out := make([]byte, 12)
+	out[0] = slot.Present
+	binary.LittleEndian.PutUint16(out[1:3], uint16(slot.Type))
+	binary.LittleEndian.PutUint32(out[3:7], slot.State)
+	binary.LittleEndian.PutUint32(out[7:11], slot.SizeKB)
+	out[11] = slot.Raw
+	return out
+}
+
+func handleCartState(st *State, payload []byte) ([]byte, error) {
+	if err := expectEmpty(payload); err != nil {
+		return nil, err
+	}
+	out := []byte{st.Cart.Autoreboot}
+	out = append(out, encodeCartSlot(st.Cart.Main)...)
+	out = append(out, encodeCartSlot(st.Cart.Piggy)...)
+	return out, nil
+}
+
+func handleJumps(st *State, payload []byte) ([]byte, error) {
+	if err := expectEmpty(payload); err != nil {
+		return nil, err
+	}
+	out := []byte{byte(len(st.Jumps))}
+	for _, pc := range st.Jumps {
+		out = append(out, u16(pc)...)
+	}
+	return out, nil
+}
+
+func handlePIAState(st *State, payload []byte) ([]byte, error) {
+	if err := expectEmpty(payload); err != nil {
+		return nil, err
+	}
+	return []byte{st.PIA.PACTL, st.PIA.PBCTL, st.PIA.PORTA, st.PIA.PORTB}, nil
+}
+
+func handlePOKEYState(st *State, payload []byte) ([]byte, error) {
+	if err := expectEmpty(payload); err != nil {
+		return nil, err
+	}
+	p := st.POKEY
+	out := []byte{p.StereoEnabled}
+	out = append(out, p.AUDF1[:]...)
+	out = append(out, p.AUDC1[:]...)
+	out = append(out, p.AUDCTL1, p.KBCODE, p.IRQEN, p.IRQST, p.SKSTAT, p.SKCTL)
+	if p.StereoEnabled != 0 {
+		out = append(out, p.AUDF2[:]...)
+		out = append(out, p.AUDC2[:]...)
+		out = append(out, p.AUDCTL2)
+	}
+	return out, nil
+}
+
+func handleStack(st *State, payload []byte) ([]byte, error) {
+	if err := expectEmpty(payload); err != nil {
+		return nil, err
+	}
+	count := 0xFF - int(st.CPU.S)
+	out := []byte{st.CPU.S, byte(count)}
+	for off := int(st.CPU.S) + 1; off <= 0xFF; off++ {
+		out = append(out, byte(off), st.Memory[0x100+off])
+	}
+	return out, nil
+}
+
+func optionalPC(st *State, payload []byte) error {
+	switch len(payload) {
+	case 0:
+		return nil
+	case 2:
+		st.CPU.PC = binary.LittleEndian.Uint16(payload)
+		return nil
+	}
+	_, err := fail(StatusInvalidLength, "expected empty or u16 payload")
+	return err
+}
+
+func handleStepOver(st *State, payload []byte) ([]byte, error) {
+	if err := optionalPC(st, payload); err != nil {
+		return nil, err
+	}
+	st.Status.Paused = true
+	st.step(true)
+	return nil, nil
+}
+
+func handleRunUntilReturn(st *State, payload []byte) ([]byte, error) {
+	if err := optionalPC(st, payload); err != nil {
+		return nil, err
+	}
+	st.Status.Paused = true
+	if st.CPU.S < 0xFE {
+		lo := st.pull()
+		hi := st.pull()
+		st.CPU.PC = (uint16(hi)<<8 | uint16(lo)) + 1
+	}
+	st.touch()
+	return nil, nil
+}
+
+func handleBBRK(st *State, payload []byte) ([]byte, error) {
+	switch len(payload) {
+	case 0:
+	case 1:
+		if payload[0] > 1 {
+			return fail(StatusInvalidValue, "enabled must be 0 or 1")
+		}
+		st.BBRK = payload[0] == 1
+	default:
+		return fail(StatusInvalidLength, "BBRK expects empty or u8 payload")
+	}
+	return []byte{boolByte(st.BBRK)}, nil
+}
+
+func handleBLine(st *State, payload []byte) ([]byte, error) {
+	switch len(payload) {
+	case 0:
+	case 2:
+		st.BLine = binary.LittleEndian.Uint16(payload)
+		// Scanlines break, 1000 plus a scanline blinks, anything else is off.
+		switch {
+		case st.BLine < 312:
+			st.BLineMode = 1
+		case st.BLine >= 1000 && st.BLine < 1312:
+			st.BLineMode = 2
+		default:
+			st.BLineMode = 0
+		}
+	default:
+		return fail(StatusInvalidLength, "BLINE expects empty or u16 payload")
+	}
+	return append(u16(st.BLine), st.BLineMode), nil
+}
+
+func handleSysinfo(st *State, payload []byte) ([]byte, error) {
+	if err := expectEmpty(payload); err != nil {
+		return nil, err
+	}
+	info := st.Sysinfo
+	flags := byte(0)
+	if info.BasicEnabled {
+		flags |= 0x01
+	}
+	if info.TVPAL {
+		flags |= 0x02
+	}
+	return []byte{flags, info.MachineFamily, info.OSRevision, info.BasicRevision, info.BuiltinGameRevision}, nil
+}
+
+func handleSearch(st *State, payload []byte) ([]byte, error) {
+	if len(payload) < 7 {
+		return fail(StatusInvalidLength, "SEARCH header missing")
+	}
+	mode := payload[0]
+	start := int(binary.LittleEndian.Uint16(payload[1:3]))
+	end := int(binary.LittleEndian.Uint16(payload[3:5]))
+	ln := int(payload[5])
+	if ln == 0 || len(payload) != 6+ln {
+		return fail(StatusInvalidLength, "SEARCH pattern length mismatch")
+	}
+	if end < start {
+		return fail(StatusInvalidValue, "end before start")
+	}
+	pattern := append([]byte(nil), payload[6:]...)
+	switch mode {
+	case 1, 2:
+	case 3:
+		for i, b := range pattern {
+			pattern[i] = atascii.ATASCIIToScreen(b)
+		}
+	default:
+		return fail(StatusInvalidValue, "invalid search mode")
+	}
+	maxAddrs := (rpc.MaxPayload - 6) / 2
+	total := uint32(0)
+	addrs := make([]byte, 0, 64)
+	for addr := start; addr+ln-1 <= end; addr++ {
+		match := true
+		for i, b := range pattern {
+			if st.Memory[addr+i] != b {
+				match = false
+				break
+			}
+		}
+		if !match {
+			continue
+		}
+		total++
+		if len(addrs)/2 < maxAddrs {
+			addrs = append(addrs, u16(uint16(addr))...)
+		}
+	}
+	out := make([]byte, 6, 6+len(addrs))
+	binary.LittleEndian.PutUint32(out[0:4], total)
+	binary.LittleEndian.PutUint16(out[4:6], uint16(len(addrs)/2))
+	return append(out, addrs...), nil
+}
+
+var setRegFlags = map[byte]byte{
+	6:  0x80,
+	7:  0x40,
+	8:  0x08,
+	9:  0x04,
+	10: 0x02,
+	11: 0x01,
+}
+
+func handleSetReg(st *State, payload []byte) ([]byte, error) {
+	if len(payload) != 3 {
+		return fail(StatusInvalidLength, "SET_REG expects target and u16 value")
+	}
+	target := payload[0]
+	value := binary.LittleEndian.Uint16(payload[1:3])
+	switch target {
+	case 1:
+		st.CPU.PC = value
+	case 2:
+		st.CPU.A = byte(value)
+	case 3:
+		st.CPU.X = byte(value)
+	case 4:
+		st.CPU.Y = byte(value)
+	case 5:
+		st.CPU.S = byte(value)
+	default:
+		mask, ok := setRegFlags[target]
+		if !ok {
+			return fail(StatusInvalidValue, "invalid register target")
+		}
+		if value > 1 {
+			return fail(StatusInvalidValue, "flag value must be 0 or 1")
+		}
+		if value == 1 {
+			st.CPU.P |= mask
+		} else {
+			st.CPU.P &^= mask
+		}
+	}
+	st.touch()
+	return nil, nil
+}
+
+func boolByte(v bool) byte {
+	if v {
+		return 1
+	}
+	return 0
+}
+
+func (st *State) push(b byte) {
+	st.Memory[0x100+uint16(st.CPU.S)] = b
+	st.CPU.S--
+}
+
+func (st *State) pull() byte {
+	st.CPU.S++
+	return st.Memory[0x100+uint16(st.CPU.S)]
+}
+
+var branchFlags = map[string]struct {
+	mask byte
+	set  bool
+}{
+	"BPL": {0x80, false}, "BMI": {0x80, true},
+	"BVC": {0x40, false}, "BVS": {0x40, true},
+	"BCC": {0x01, false}, "BCS": {0x01, true},
+	"BNE": {0x02, false}, "BEQ": {0x02, true},
+}
+
+// step advances PC over one instruction. Registers other than PC and S are
+// left untouched; control flow follows JMP/JSR/RTS/RTI and flag-driven
+// branches so that stepping through code behaves plausibly.
+func (st *State) step(over bool) {
+	pc := st.CPU.PC
+	code := st.read(pc, 3)
+	ins := disasm.DecodeOne(pc, code, true)
+	st.History = append([]rpc.HistoryEntry{{
+		Y:   byte(st.CPU.YPos >> 8),
+		X:   byte(st.CPU.XPos),
+		PC:  pc,
+		Op0: code[0],
+		Op1: code[1],
+		Op2: code[2],
+	}}, st.History...)
+	if len(st.History) > historySteps {
+		st.History = st.History[:historySteps]
+	}
+	next := pc + uint16(ins.Size)
+	switch ins.Mnemonic {
+	case "JMP":
+		st.recordJump(pc)
+		next = *ins.FlowTarget
+		if ins.Addressing == "ind" {
+			next = st.word(*ins.FlowTarget)
+		}
+	case "JSR":
+		st.recordJump(pc)
+		if !over {
+			ret := pc + 2
+			st.push(byte(ret >> 8))
+			st.push(byte(ret))
+			next = *ins.FlowTarget
+		}
+	case "RTS":
+		lo := st.pull()
+		hi := st.pull()
+		next = (uint16(hi)<<8 | uint16(lo)) + 1
+	case "RTI":
+		st.CPU.P = st.pull()
+		lo := st.pull()
+		hi := st.pull()
+		next = uint16(hi)<<8 | uint16(lo)
+	default:
+		if cond, ok := branchFlags[ins.Mnemonic]; ok && ins.FlowTarget != nil {
+			if (st.CPU.P&cond.mask != 0) == cond.set {
+				next = *ins.FlowTarget
+			}
+		}
+	}
+	st.CPU.PC = next
+	st.touch()
+}
+
+func (st *State) recordJump(pc uint16) {
+	st.Jumps = append(st.Jumps, pc)
+	if len(st.Jumps) > jumpSteps {
+		st.Jumps = st.Jumps[len(st.Jumps)-jumpSteps:]
+	}
+}
@@ -0,0 +1,194 @@
+package rpctest
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"go800mon/internal/rpc"
+)
+
+const maxClients = 8
+
+type Server struct {
+	path     string
+	tempDir  string
+	listener net.Listener
+
+	mu    sync.Mutex
+	state State
+	calls map[rpc.Command]int
+	conns map[net.Conn]struct{}
+	wg    sync.WaitGroup
+}
+
+// NewServer starts a fake Remote Monitor on a UNIX socket at path. An empty
+// path places the socket in a fresh temporary directory.
+func NewServer(path string) (*Server, error) {
+	tempDir := ""
+	if path == "" {
+		dir, err := os.MkdirTemp("", "rpctest")
+		if err != nil {
+			return nil, err
+		}
+		tempDir = dir
+		path = filepath.Join(dir, "atari.sock")
+	}
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		if tempDir != "" {
+			_ = os.RemoveAll(tempDir)
+		}
+		return nil, err
+	}
+	s := &Server{
+		path:     path,
+		tempDir:  tempDir,
+		listener: ln,
+		state:    NewState(),
+		calls:    map[rpc.Command]int{},
+		conns:    map[net.Conn]struct{}{},
+	}
+	s.wg.Add(1)
+	go s.acceptLoop()
+	return s, nil
+}
+
+func (s *Server) Path() string {
+	return s.path
+}
+
+func (s *Server) Close() error {
+	err := s.listener.Close()
+	s.mu.Lock()
+	for conn := range s.conns {
+		_ = conn.Close()
+	}
+	s.mu.Unlock()
+	s.wg.Wait()
+	_ = os.Remove(s.path)
+	if s.tempDir != "" {
+		_ = os.RemoveAll(s.tempDir)
+	}
+	return err
+}
+
+// State returns a copy of the emulated machine state.
+func (s *Server) State() State {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.state.clone()
+}
+
+// Update mutates the emulated machine state under the server lock.
+func (s *Server) Update(fn func(*State)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	fn(&s.state)
+}
+
+// Calls returns how many cmd requests the server has handled.
+func (s *Server) Calls(cmd rpc.Command) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.calls[cmd]
+}
+
+func (s *Server) LoadMemory(addr uint16, data []byte) {
+	s.Update(func(st *State) {
+		for i, b := range data {
+			st.Memory[uint16(int(addr)+i)] = b
+		}
+	})
+}
+
+func (s *Server) acceptLoop() {
+	defer s.wg.Done()
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		s.mu.Lock()
+		if len(s.conns) >= maxClients {
+			s.mu.Unlock()
+			_ = conn.Close()
+			continue
+		}
+		s.conns[conn] = struct{}{}
+		s.mu.Unlock()
+		s.wg.Add(1)
+		go s.serve(conn)
+	}
+}
+
+func (s *Server) serve(conn net.Conn) {
+	defer s.wg.Done()
+	defer func() {
+		s.mu.Lock()
+		delete(s.conns, conn)
+		s.mu.Unlock()
+		_ = conn.Close()
+	}()
+	hdr := make([]byte, 3)
+	for {
+		if _, err := io.ReadFull(conn, hdr); err != nil {
+			return
+		}
+		ln := int(binary.LittleEndian.Uint16(hdr[1:3]))
+		if ln > rpc.MaxPayload {
+			return
+		}
+		payload := make([]byte, ln)
+		if _, err := io.ReadFull(conn, payload); err != nil {
+			return
+		}
+		status, data := s.handle(rpc.Command(hdr[0]), payload)
+		if len(data) > rpc.MaxPayload {
+			status, data = StatusPayloadTooLarge, []byte("response too large")
+		}
+		out := make([]byte, 3+len(data))
+		out[0] = status
+		binary.LittleEndian.PutUint16(out[1:3], uint16(len(data)))
+		copy(out[3:], data)
+		if _, err := conn.Write(out); err != nil {
+			return
+		}
+	}
+}
+
+type commandError struct {
+	status byte
+	msg    string
+}
+
+func (e commandError) Error() string {
+	return e.msg
+}
+
+func fail(status byte, msg string) ([]byte, error) {
+	return nil, commandError{status: status, msg: msg}
+}
+
+func (s *Server) handle(cmd rpc.Command, payload []byte) (byte, []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.calls[cmd]++
+	h, ok := handlers[cmd]
+	if !ok {
+		return StatusUnknownCommand, []byte("unknown command")
+	}
+	data, err := h(&s.state, payload)
+	if err != nil {
+		var cerr commandError
+		if errors.As(err, &cerr) {
+			return cerr.status, []byte(cerr.msg)
+		}
+		return StatusGeneric, []byte(err.Error())
+	}
+	return StatusOK, data
+}
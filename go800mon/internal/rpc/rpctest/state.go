@@ -0,0 +1,89 @@
+package rpctest
+
+import "go800mon/internal/rpc"
+
+const (
+	StatusOK              byte = 0
+	StatusGeneric         byte = 1
+	StatusInvalidLength   byte = 2
+	StatusInvalidValue    byte = 3
+	StatusPayloadTooLarge byte = 4
+	StatusFileNotFound    byte = 5
+	StatusFileOpenFailed  byte = 6
+	StatusFileRunFailed   byte = 7
+	StatusUnsupportedFile byte = 8
+	StatusUnknownCommand  byte = 9
+)
+
+type State struct {
+	Memory         [0x10000]byte
+	CPU            rpc.CPUState
+	Status         rpc.Status
+	Sysinfo        rpc.Sysinfo
+	GTIA           rpc.GTIAState
+	ANTIC          rpc.ANTICState
+	PIA            rpc.PIAState
+	POKEY          rpc.POKEYState
+	Cart           rpc.CartState
+	Jumps          []uint16
+	History        []rpc.HistoryEntry
+	Breakpoints    rpc.BreakpointList
+	BuildFeatures  []uint16
+	BuiltinMonitor bool
+	BBRK           bool
+	BLine          uint16
+	BLineMode      byte
+	LastRun        string
+	DisksMounted   []bool
+	TapeMounted    bool
+	Stopped        bool
+	Restarted      bool
+}
+
+func NewState() State {
+	st := State{
+		CPU: rpc.CPUState{PC: 0xE477, S: 0xFF, P: 0x34},
+		Status: rpc.Status{
+			MachineType: 4,
+		},
+		Sysinfo: rpc.Sysinfo{
+			MachineFamily:       1,
+			OSRevision:          0x22,
+			BasicRevision:       0x02,
+			BuiltinGameRevision: 0xFF,
+		},
+		ANTIC: rpc.ANTICState{
+			DMACTL: 0x22,
+			CHBASE: 0xE0,
+			NMIEN:  0x40,
+		},
+		PIA: rpc.PIAState{
+			PACTL: 0x3C,
+			PBCTL: 0x3C,
+			PORTA: 0xFF,
+			PORTB: 0xFF,
+		},
+		Breakpoints:   rpc.BreakpointList{Enabled: true},
+		BLine:         999,
+		BuildFeatures: []uint16{0x0001, 0x0002, 0x0003, 0x0004, 0x0005},
+		DisksMounted:  make([]bool, 8),
+	}
+	return st
+}
+
+func (st *State) clone() State {
+	out := *st
+	out.Jumps = append([]uint16(nil), st.Jumps...)
+	out.History = append([]rpc.HistoryEntry(nil), st.History...)
+	out.BuildFeatures = append([]uint16(nil), st.BuildFeatures...)
+	out.DisksMounted = append([]bool(nil), st.DisksMounted...)
+	out.Breakpoints.Clauses = make([][]rpc.BreakpointCondition, 0, len(st.Breakpoints.Clauses))
+	for _, clause := range st.Breakpoints.Clauses {
+		out.Breakpoints.Clauses = append(out.Breakpoints.Clauses, append([]rpc.BreakpointCondition(nil), clause...))
+	}
+	return out
+}
+
+func (st *State) touch() {
+	st.Status.StateSeq = uint64(uint32(st.Status.StateSeq + 1))
+}
@@ -0,0 +1,65 @@
+package video
+
+import (
+	"image"
+	"image/color"
+)
+
+// CharsetSize is the length of a 128-glyph character set.
+const CharsetSize = 0x400
+
+const charsetColumns = 16
+
+// GlyphText renders glyph code of font as 8 rows, '#' for set pixels and '.'
+// for clear ones. Multicolor glyphs (ANTIC 4/5) have 4 pixels per row shown
+// as the color index 1-3, each two characters wide.
+func GlyphText(font []byte, code byte, multicolor bool) [8]string {
+	var out [8]string
+	for y := range out {
+		bits := font[int(code&0x7F)*8+y]
+		row := make([]byte, 8)
+		for x := range row {
+			if multicolor {
+				row[x] = ".123"[bits>>(6-2*(x/2))&0x03]
+			} else if bits&(0x80>>x) != 0 {
+				row[x] = '#'
+			} else {
+				row[x] = '.'
+			}
+		}
+		out[y] = string(row)
+	}
+	return out
+}
+
+// CharsetImage draws the 128 glyphs of font in a 16x8 grid at one pixel per
+// hi-res pixel, colored as ANTIC mode 2 or, with multicolor, mode 4 would.
+func CharsetImage(font []byte, multicolor bool, regs Registers, palette *[256]color.RGBA) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, charsetColumns*8, 128/charsetColumns*8))
+	for code := 0; code < 128; code++ {
+		ox, oy := code%charsetColumns*8, code/charsetColumns*8
+		for y, row := range regs.GlyphColors(font, byte(code), multicolor) {
+			for x, c := range row {
+				img.SetRGBA(ox+x, oy+y, palette[c])
+			}
+		}
+	}
+	return img
+}
+
+// GlyphColors returns the color register value of each hi-res pixel of glyph
+// code, as ANTIC mode 2 or, with multicolor, mode 4 shows it.
+func (r Registers) GlyphColors(font []byte, code byte, multicolor bool) [8][8]byte {
+	var out [8][8]byte
+	for y := range out {
+		bits := font[int(code&0x7F)*8+y]
+		for x := range out[y] {
+			if multicolor {
+				out[y][x] = r.playfield(bits >> (6 - 2*(x/2)) & 0x03)
+			} else {
+				out[y][x] = r.hires(bits&(0x80>>x) != 0)
+			}
+		}
+	}
+	return out
+}
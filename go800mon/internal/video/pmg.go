@@ -0,0 +1,153 @@
+package video
+
+import (
+	"fmt"
+	"strings"
+
+	"go800mon/internal/rpc"
+)
+
+// PMLayout locates player/missile graphics in memory for a PMBASE and DMACTL
+// pair. Single-line resolution uses a 2K area with 256 rows per object,
+// double-line resolution a 1K area with 128 rows.
+type PMLayout struct {
+	Base       uint16
+	SingleLine bool
+}
+
+func NewPMLayout(pmbase, dmactl byte) PMLayout {
+	if dmactl&0x10 != 0 {
+		return PMLayout{Base: uint16(pmbase&0xF8) << 8, SingleLine: true}
+	}
+	return PMLayout{Base: uint16(pmbase&0xFC) << 8}
+}
+
+// Size is the length of the P/M area.
+func (l PMLayout) Size() int {
+	if l.SingleLine {
+		return 0x800
+	}
+	return 0x400
+}
+
+// Rows is the number of bytes per player or missile.
+func (l PMLayout) Rows() int {
+	return l.Size() / 8
+}
+
+// Missiles is the address of the shared missile bitmap.
+func (l PMLayout) Missiles() uint16 {
+	return l.Base + uint16(3*l.Rows())
+}
+
+// Player is the address of the bitmap of player n.
+func (l PMLayout) Player(n int) uint16 {
+	return l.Base + uint16((4+n)*l.Rows())
+}
+
+// PMObject is a player or missile with its GTIA position, size and color.
+// Without DMA GTIA repeats the GRAFP/GRAFM register on every line, so Rows
+// then holds just that value.
+type PMObject struct {
+	Name  string
+	Addr  uint16
+	HPOS  byte
+	Width int
+	Color byte
+	DMA   bool
+	Bits  int
+	Rows  []byte
+}
+
+// pmWidths maps a 2-bit SIZEP/SIZEM value to the pixel width in color clocks.
+var pmWidths = [4]int{1, 2, 1, 4}
+
+// PMObjects decodes the four players and four missiles from area, the memory
+// at layout.Base. Missile bits are moved to the top of each row. Missiles
+// take the color of their player, or COLPF3 when PRIOR enables the fifth
+// player.
+func PMObjects(area []byte, layout PMLayout, gtia rpc.GTIAState, dmactl byte) []PMObject {
+	out := make([]PMObject, 0, 8)
+	rows := layout.Rows()
+	for n := 0; n < 4; n++ {
+		obj := PMObject{
+			Name:  fmt.Sprintf("P%d", n),
+			Addr:  layout.Player(n),
+			HPOS:  gtia.HPOSP[n],
+			Width: pmWidths[gtia.SIZEP[n]&0x03],
+			Color: gtia.COLPM[n],
+			DMA:   dmactl&0x08 != 0 && gtia.GRACTL&0x02 != 0,
+			Bits:  8,
+			Rows:  []byte{gtia.GRAFP[n]},
+		}
+		if obj.DMA {
+			off := int(obj.Addr - layout.Base)
+			obj.Rows = area[off : off+rows]
+		}
+		out = append(out, obj)
+	}
+	for n := 0; n < 4; n++ {
+		obj := PMObject{
+			Name:  fmt.Sprintf("M%d", n),
+			Addr:  layout.Missiles(),
+			HPOS:  gtia.HPOSM[n],
+			Width: pmWidths[gtia.SIZEM>>(2*n)&0x03],
+			Color: gtia.COLPM[n],
+			DMA:   dmactl&0x04 != 0 && gtia.GRACTL&0x01 != 0,
+			Bits:  2,
+		}
+		if gtia.PRIOR&0x10 != 0 {
+			obj.Color = gtia.COLPF[3]
+		}
+		src := []byte{gtia.GRAFM}
+		if obj.DMA {
+			off := int(obj.Addr - layout.Base)
+			src = area[off : off+rows]
+		}
+		obj.Rows = make([]byte, len(src))
+		for i, v := range src {
+			obj.Rows[i] = v << (6 - 2*n) & 0xC0
+		}
+		out = append(out, obj)
+	}
+	return out
+}
+
+// Describe summarizes the position, size, color and source of o.
+func (o PMObject) Describe() string {
+	source := fmt.Sprintf("DMA %04X", o.Addr)
+	if !o.DMA {
+		source = fmt.Sprintf("no DMA, GRAF %02X", o.Rows[0])
+	}
+	return fmt.Sprintf("%s X=%02X W=%dx C=%02X %s", o.Name, o.HPOS, o.Width, o.Color, source)
+}
+
+// Lines draws the rows from the first to the last non-empty one, each
+// prefixed with its row number. Pixels are '#' repeated by the width.
+func (o PMObject) Lines() []string {
+	first, last := -1, -1
+	for i, v := range o.Rows {
+		if v != 0 {
+			if first < 0 {
+				first = i
+			}
+			last = i
+		}
+	}
+	if first < 0 {
+		return nil
+	}
+	out := make([]string, 0, last-first+1)
+	for i := first; i <= last; i++ {
+		var b strings.Builder
+		for bit := 0; bit < o.Bits; bit++ {
+			ch := "."
+			if o.Rows[i]&(0x80>>bit) != 0 {
+				ch = "#"
+			}
+			b.WriteString(strings.Repeat(ch, o.Width))
+		}
+		out = append(out, fmt.Sprintf("%02X %s", i, b.String()))
+	}
+	return out
+}
@@ -0,0 +1,194 @@
+// Package video renders an Atari screen from the display list, the ANTIC and
+// GTIA registers and a memory image.
+package video
+
+import (
+	"image"
+	"image/color"
+	"math"
+
+	dl "go800mon/internal/displaylist"
+)
+
+// Registers holds the ANTIC and GTIA state the renderer needs.
+type Registers struct {
+	DMACTL byte
+	CHACTL byte
+	CHBASE byte
+	PRIOR  byte
+	COLBK  byte
+	COLPF  [4]byte
+	COLPM  [4]byte
+}
+
+// Palettes map Atari color register values to RGB. They are computed from
+// the GTIA hue and luminance steps and approximate what a TV shows.
+var (
+	NTSCPalette = newPalette(25.7)
+	PALPalette  = newPalette(24)
+)
+
+func newPalette(hueStep float64) [256]color.RGBA {
+	var out [256]color.RGBA
+	clamp := func(v float64) uint8 {
+		return uint8(math.Round(255 * math.Max(0, math.Min(1, v))))
+	}
+	for c := range out {
+		hue, lum := c>>4, c&0x0F
+		y := float64(lum) / 15
+		var u, v float64
+		if hue > 0 {
+			// Hue 1 is gold; hues step through red, blue and green back to orange.
+			angle := (146 - float64(hue-1)*hueStep) * math.Pi / 180
+			u, v = 0.25*math.Cos(angle), 0.25*math.Sin(angle)
+		}
+		out[c] = color.RGBA{
+			R: clamp(y + 1.140*v),
+			G: clamp(y - 0.395*u - 0.581*v),
+			B: clamp(y + 2.032*u),
+			A: 0xFF,
+		}
+	}
+	return out
+}
+
+// mapPixel is the width of a map mode pixel in hi-res pixels.
+var mapPixel = [16]int{8: 8, 9: 4, 0xA: 4, 0xB: 2, 0xC: 2, 0xD: 2, 0xE: 2}
+
+// Render draws dlist at one pixel per hi-res pixel and one row per scanline.
+// mem must be a 64K image holding at least the screen rows and the character
+// set. Vertical and horizontal fine scrolling are not applied.
+func Render(dlist dl.DisplayList, regs Registers, mem []byte, palette *[256]color.RGBA) *image.RGBA {
+	mapper := dl.NewMemoryMapper(dlist, regs.DMACTL, 4096)
+	width := mapper.BytesPerLine(2) * 8
+	rows := mapper.RowRanges()
+	height := 0
+	for _, row := range rows {
+		height += max(1, dl.ModeLines[row.Mode])
+	}
+	img := image.NewRGBA(image.Rect(0, 0, max(1, width), max(1, height)))
+	y := 0
+	for _, row := range rows {
+		lines := max(1, dl.ModeLines[row.Mode])
+		for line := 0; line < lines; line++ {
+			for x := 0; x < width; x++ {
+				img.SetRGBA(x, y, palette[regs.colorAt(row, line, x, mem)])
+			}
+			y++
+		}
+	}
+	return img
+}
+
+// colorAt returns the color register value of hi-res pixel x on scanline line
+// of row.
+func (r Registers) colorAt(row dl.RowRange, line, x int, mem []byte) byte {
+	if row.Addr == nil || row.Mode < 2 {
+		return r.COLBK
+	}
+	at := func(i int) byte {
+		return mem[uint16(int(*row.Addr)+i)]
+	}
+	switch row.Mode {
+	case 2, 3:
+		code := at(x / 8)
+		data := r.glyphRow(code, row.Mode, line, mem)
+		return r.hires(data&(0x80>>(x%8)) != 0)
+	case 4, 5:
+		code := at(x / 8)
+		if row.Mode == 5 {
+			line /= 2
+		}
+		data := mem[uint16(r.CHBASE&0xFC)<<8|uint16(code&0x7F)<<3|uint16(r.reflect(line))]
+		bits := data >> (6 - 2*((x/2)%4)) & 0x03
+		if bits == 3 && code&0x80 != 0 {
+			return r.COLPF[3] & 0xFE
+		}
+		return r.playfield(bits)
+	case 6, 7:
+		code := at(x / 16)
+		if row.Mode == 7 {
+			line /= 2
+		}
+		data := mem[uint16(r.CHBASE&0xFE)<<8|uint16(code&0x3F)<<3|uint16(r.reflect(line))]
+		if data&(0x80>>((x/2)%8)) == 0 {
+			return r.COLBK
+		}
+		return r.COLPF[code>>6] & 0xFE
+	case 8, 0xA, 0xD, 0xE:
+		idx := x / mapPixel[row.Mode]
+		return r.playfield(at(idx/4) >> (6 - 2*(idx%4)) & 0x03)
+	case 9, 0xB, 0xC:
+		idx := x / mapPixel[row.Mode]
+		return r.playfield(at(idx/8) >> (7 - idx%8) & 0x01)
+	}
+	data := at(x / 8)
+	switch r.PRIOR >> 6 {
+	case 1:
+		return r.COLBK&0xF0 | data>>(4-4*((x/4)%2))&0x0F
+	case 2:
+		n := data >> (4 - 4*((x/4)%2)) & 0x0F
+		switch {
+		case n < 4:
+			return r.COLPM[n] & 0xFE
+		case n < 8:
+			return r.COLPF[n-4] & 0xFE
+		}
+		return r.COLBK & 0xFE
+	case 3:
+		n := data >> (4 - 4*((x/4)%2)) & 0x0F
+		return n<<4 | r.COLBK&0x0F
+	}
+	return r.hires(data&(0x80>>(x%8)) != 0)
+}
+
+// playfield returns the color of a 2-bit pixel: COLBK, then COLPF0-2.
+func (r Registers) playfield(i byte) byte {
+	if i == 0 {
+		return r.COLBK
+	}
+	return r.COLPF[i-1] & 0xFE
+}
+
+// hires returns the color of a hi-res pixel, which takes its luminance from
+// COLPF1 and its hue from the COLPF2 background.
+func (r Registers) hires(bit bool) byte {
+	if bit {
+		return r.COLPF[2]&0xF0 | r.COLPF[1]&0x0E
+	}
+	return r.COLPF[2] & 0xFE
+}
+
+// glyphRow returns the bits of scanline line of a mode 2 or 3 character,
+// applying CHACTL and the mode 3 descenders.
+func (r Registers) glyphRow(code, mode byte, line int, mem []byte) byte {
+	if mode == 3 {
+		switch {
+		case code&0x7F >= 0x60 && line < 2, code&0x7F < 0x60 && line >= 8:
+			line = -1
+		case line >= 8:
+			line -= 8
+		}
+	}
+	var data byte
+	if line >= 0 {
+		data = mem[uint16(r.CHBASE&0xFC)<<8|uint16(code&0x7F)<<3|uint16(r.reflect(line))]
+	}
+	if code&0x80 != 0 {
+		if r.CHACTL&0x01 != 0 {
+			data = 0
+		}
+		if r.CHACTL&0x02 != 0 {
+			data = ^data
+		}
+	}
+	return data
+}
+
+// reflect applies the CHACTL vertical reflect bit to a glyph row.
+func (r Registers) reflect(line int) int {
+	if r.CHACTL&0x04 != 0 {
+		return 7 - line
+	}
+	return line
+}